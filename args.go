@@ -0,0 +1,266 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// commandArgs is a command's parsed arguments: the positional arguments and
+// the values of the flags it declared.
+type commandArgs struct {
+	positional []string
+	flags      *flag.FlagSet
+}
+
+// arg returns positional argument i, or "" if there is none.
+func (a commandArgs) arg(i int) string {
+	if i >= len(a.positional) {
+		return ""
+	}
+	return a.positional[i]
+}
+
+// flag returns the value of a declared flag as a string.
+func (a commandArgs) flag(name string) string {
+	if a.flags == nil {
+		return ""
+	}
+	f := a.flags.Lookup(name)
+	if f == nil {
+		return ""
+	}
+	return f.Value.String()
+}
+
+// intFlag returns the value of a declared integer flag.
+func (a commandArgs) intFlag(name string) int {
+	n, _ := strconv.Atoi(a.flag(name))
+	return n
+}
+
+// boolFlag returns the value of a declared boolean flag.
+func (a commandArgs) boolFlag(name string) bool {
+	b, _ := strconv.ParseBool(a.flag(name))
+	return b
+}
+
+// usageError reports arguments that do not match a command's declaration.
+type usageError struct {
+	command cliCommand
+	msg     string
+}
+
+func (e usageError) Error() string {
+	return fmt.Sprintf("%s (usage: %s)", e.msg, e.command.synopsis())
+}
+
+// synopsis returns a one-line summary of how to call the command, such as
+// "sync [flags] [resource...]".
+func (c cliCommand) synopsis() string {
+	parts := []string{c.name}
+	if c.flags != nil {
+		parts = append(parts, "[flags]")
+	}
+	if c.usage != "" {
+		parts = append(parts, c.usage)
+	}
+	return strings.Join(parts, " ")
+}
+
+// flagSet returns a new flag set holding the command's declared flags.
+func (c cliCommand) flagSet() *flag.FlagSet {
+	flags := flag.NewFlagSet(c.name, flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	if c.flags != nil {
+		c.flags(flags)
+	}
+	return flags
+}
+
+// parse checks args against the command's flags and arity.
+func (c cliCommand) parse(args []string) (commandArgs, error) {
+	flags := c.flagSet()
+
+	var positional []string
+	var err error
+	if c.flagsFirst {
+		positional, err = parseArgs(flags, args)
+	} else {
+		positional, err = parseCommandFlags(flags, args)
+	}
+	if errors.Is(err, flag.ErrHelp) {
+		return commandArgs{}, usageError{c, "help requested"}
+	}
+	if err != nil {
+		return commandArgs{}, usageError{c, err.Error()}
+	}
+
+	if len(positional) < c.minArgs {
+		return commandArgs{}, usageError{c, "not enough arguments"}
+	}
+	if c.maxArgs >= 0 && len(positional) > c.maxArgs {
+		return commandArgs{}, usageError{c, "too many arguments"}
+	}
+
+	return commandArgs{positional: positional, flags: flags}, nil
+}
+
+// parseCommandFlags parses a command's flags from anywhere in its
+// arguments, so they can follow the positional arguments as well as
+// precede them, and returns the positional arguments. Everything after
+// "--" is an argument even if it looks like a flag.
+func parseCommandFlags(flags *flag.FlagSet, args []string) ([]string, error) {
+	var rest []string
+	for i, arg := range args {
+		if arg == "--" {
+			args, rest = args[:i], args[i+1:]
+			break
+		}
+	}
+
+	var positional []string
+	for {
+		if err := flags.Parse(args); err != nil {
+			return nil, err
+		}
+
+		args = flags.Args()
+		if len(args) == 0 {
+			break
+		}
+
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+
+	return append(positional, rest...), nil
+}
+
+// splitArgs splits a command line into words the way a shell would: words
+// are separated by spaces, single quotes keep everything literally, double
+// quotes keep spaces, and a backslash escapes the next character outside
+// single quotes.
+func splitArgs(input string) ([]string, error) {
+	links, err := splitWords(input, false)
+	if err != nil {
+		return nil, err
+	}
+	return links[0].stages[0], nil
+}
+
+// A chainLink is one pipeline of a command line such as
+// "catch pikachu && inspect pikachu; pokedex | count".
+type chainLink struct {
+	stages [][]string
+	// andThen is set for a pipeline after "&&", which only runs if the one
+	// before it succeeded. One after ";" always runs.
+	andThen bool
+}
+
+// empty reports whether the link has no command at all.
+func (l chainLink) empty() bool {
+	return len(l.stages) == 1 && len(l.stages[0]) == 0
+}
+
+// splitCommandLine splits a command line into pipelines separated by ";" or
+// "&&" outside quotes, each pipeline into commands separated by "|", and
+// each command into words as splitArgs does.
+func splitCommandLine(input string) ([]chainLink, error) {
+	links, err := splitWords(input, true)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, link := range links {
+		if link.andThen && (link.empty() || links[i-1].empty()) {
+			return nil, errors.New(`"&&" needs a command on each side`)
+		}
+	}
+
+	return links, nil
+}
+
+func splitWords(input string, operators bool) ([]chainLink, error) {
+	var links []chainLink
+	var link chainLink
+	var args []string
+	var word strings.Builder
+	inWord := false
+	var quote rune
+	escaped := false
+
+	endWord := func() {
+		if inWord {
+			args = append(args, word.String())
+			word.Reset()
+			inWord = false
+		}
+	}
+
+	runes := []rune(input)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case escaped:
+			word.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped = true
+			inWord = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inWord = true
+		case r == ' ' || r == '\t' || r == '\n' || r == '\r':
+			endWord()
+		case operators && r == '|':
+			endWord()
+			link.stages = append(link.stages, args)
+			args = nil
+		case operators && (r == ';' || r == '&' && i+1 < len(runes) && runes[i+1] == '&'):
+			endWord()
+			link.stages = append(link.stages, args)
+			links = append(links, link)
+			link = chainLink{andThen: r == '&'}
+			args = nil
+			if r == '&' {
+				i++
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote", quote)
+	}
+	if escaped {
+		return nil, errors.New("trailing backslash")
+	}
+	endWord()
+	link.stages = append(link.stages, args)
+
+	return append(links, link), nil
+}
+
+// continuesLine reports whether line ends in a backslash that is not itself
+// escaped, which continues the command on the next line.
+func continuesLine(line string) bool {
+	return (len(line)-len(strings.TrimRight(line, `\`)))%2 == 1
+}
+
+// resourceName turns a user-typed name such as "Mr Mime" into the form the
+// API uses, "mr-mime".
+func resourceName(name string) string {
+	return strings.Join(strings.Fields(strings.ToLower(name)), "-")
+}
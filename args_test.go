@@ -0,0 +1,195 @@
+package main
+
+import (
+	"flag"
+	"reflect"
+	"testing"
+)
+
+func TestSplitArgs(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected []string
+		err      string
+	}{
+		{input: "  catch  Pikachu ", expected: []string{"catch", "Pikachu"}},
+		{input: `catch "mr mime"`, expected: []string{"catch", "mr mime"}},
+		{input: `explore 'eterna forest'`, expected: []string{"explore", "eterna forest"}},
+		{input: `catch mr\ mime`, expected: []string{"catch", "mr mime"}},
+		{input: `say "it's" 'a "quote"'`, expected: []string{"say", "it's", `a "quote"`}},
+		{input: `catch ""`, expected: []string{"catch", ""}},
+		{input: "", expected: nil},
+		{input: `catch "mr mime`, err: `unterminated " quote`},
+		{input: `catch mr\`, err: "trailing backslash"},
+		{input: "grep a|b", expected: []string{"grep", "a|b"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.input, func(t *testing.T) {
+			actual, err := splitArgs(c.input)
+			if c.err != "" {
+				if err == nil || err.Error() != c.err {
+					t.Errorf("expected error %q, got %v", c.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(actual, c.expected) {
+				t.Errorf("expected %q, got %q", c.expected, actual)
+			}
+		})
+	}
+}
+
+func TestSplitCommandLine(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected []chainLink
+		err      string
+	}{
+		{input: "pokedex", expected: []chainLink{{stages: [][]string{{"pokedex"}}}}},
+		{input: "pokedex | where type=water|sort weight", expected: []chainLink{{stages: [][]string{{"pokedex"}, {"where", "type=water"}, {"sort", "weight"}}}}},
+		{input: `help | grep "a|b" | grep c\|d`, expected: []chainLink{{stages: [][]string{{"help"}, {"grep", "a|b"}, {"grep", "c|d"}}}}},
+		{input: "map |", expected: []chainLink{{stages: [][]string{{"map"}, nil}}}},
+		{
+			input: "catch pikachu && inspect pikachu; pokedex | count",
+			expected: []chainLink{
+				{stages: [][]string{{"catch", "pikachu"}}},
+				{stages: [][]string{{"inspect", "pikachu"}}, andThen: true},
+				{stages: [][]string{{"pokedex"}, {"count"}}},
+			},
+		},
+		{
+			input: `map;mapb&&help 'a;b' "c&&d" e\;f g&h`,
+			expected: []chainLink{
+				{stages: [][]string{{"map"}}},
+				{stages: [][]string{{"mapb"}}},
+				{stages: [][]string{{"help", "a;b", "c&&d", "e;f", "g&h"}}, andThen: true},
+			},
+		},
+		{input: "map;", expected: []chainLink{{stages: [][]string{{"map"}}}, {stages: [][]string{nil}}}},
+		{input: "map &&", err: `"&&" needs a command on each side`},
+		{input: "&& map", err: `"&&" needs a command on each side`},
+	}
+
+	for _, c := range cases {
+		t.Run(c.input, func(t *testing.T) {
+			actual, err := splitCommandLine(c.input)
+			if c.err != "" {
+				if err == nil || err.Error() != c.err {
+					t.Errorf("expected error %q, got %v", c.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(actual, c.expected) {
+				t.Errorf("expected %+v, got %+v", c.expected, actual)
+			}
+		})
+	}
+}
+
+func TestContinuesLine(t *testing.T) {
+	cases := map[string]bool{
+		"map":     false,
+		`map \`:   true,
+		`map \\`:  false,
+		`map \\\`: true,
+		"":        false,
+	}
+
+	for line, expected := range cases {
+		if actual := continuesLine(line); actual != expected {
+			t.Errorf("continuesLine(%q): expected %t, got %t", line, expected, actual)
+		}
+	}
+}
+
+func TestCommandParse(t *testing.T) {
+	command := cliCommand{
+		name:    "sync",
+		usage:   "<first> [rest...]",
+		minArgs: 1,
+		maxArgs: 3,
+		flags: func(flags *flag.FlagSet) {
+			flags.Int("workers", 8, "")
+			flags.Bool("force", false, "")
+		},
+	}
+
+	cases := []struct {
+		name       string
+		args       []string
+		positional []string
+		workers    int
+		force      bool
+		err        string
+	}{
+		{
+			name:       "defaults",
+			args:       []string{"pokemon"},
+			positional: []string{"pokemon"},
+			workers:    8,
+		},
+		{
+			name:       "flags anywhere",
+			args:       []string{"pokemon", "--workers", "2", "type", "-force"},
+			positional: []string{"pokemon", "type"},
+			workers:    2,
+			force:      true,
+		},
+		{
+			name:       "arguments after --",
+			args:       []string{"pokemon", "--", "--workers"},
+			positional: []string{"pokemon", "--workers"},
+			workers:    8,
+		},
+		{
+			name: "not enough arguments",
+			err:  "not enough arguments (usage: sync [flags] <first> [rest...])",
+		},
+		{
+			name: "too many arguments",
+			args: []string{"a", "b", "c", "d"},
+			err:  "too many arguments (usage: sync [flags] <first> [rest...])",
+		},
+		{
+			name: "unknown flag",
+			args: []string{"pokemon", "--fast"},
+			err:  "flag provided but not defined: -fast (usage: sync [flags] <first> [rest...])",
+		},
+		{
+			name: "bad flag value",
+			args: []string{"pokemon", "--workers", "many"},
+			err:  `invalid value "many" for flag -workers: parse error (usage: sync [flags] <first> [rest...])`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			args, err := command.parse(c.args)
+			if c.err != "" {
+				if err == nil || err.Error() != c.err {
+					t.Errorf("expected error %q, got %v", c.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(args.positional, c.positional) {
+				t.Errorf("expected positional %q, got %q", c.positional, args.positional)
+			}
+			if args.intFlag("workers") != c.workers {
+				t.Errorf("expected workers %d, got %d", c.workers, args.intFlag("workers"))
+			}
+			if args.boolFlag("force") != c.force {
+				t.Errorf("expected force %t, got %t", c.force, args.boolFlag("force"))
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+)
+
+// parseArgs parses the program's flags, which come before the command,
+// and returns the command and its arguments. Parsing stops at the first
+// argument that is not a flag, so the command's own flags are left for it,
+// or after "--".
+func parseArgs(flags *flag.FlagSet, args []string) ([]string, error) {
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+	return flags.Args(), nil
+}
+
+// runOneShot runs a single command given on the OS command line, such as
+// "pokedexcli explore pastoria-city-area", and returns the exit status.
+func runOneShot(cfg *config, args []string) int {
+	err := runCommand(cfg, args)
+	if err != nil && !errors.Is(err, errExit) {
+		fmt.Fprintln(cfg.errOut, errorText(errColors, err))
+		return 1
+	}
+
+	return 0
+}
+
+func usage() {
+	out := flag.CommandLine.Output()
+
+	fmt.Fprintf(out, "Usage: %s [flags] [command [args...]]\n\n", os.Args[0])
+	fmt.Fprintln(out, "Without a command, starts the interactive Pokedex.")
+	printCommandList(out)
+
+	fmt.Fprintln(out, "\nFlags:")
+	flag.PrintDefaults()
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"flag"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseArgs(t *testing.T) {
+	cases := []struct {
+		args     []string
+		expected []string
+		backend  string
+	}{
+		{args: []string{}, expected: []string{}},
+		{args: []string{"explore", "pastoria-city-area"}, expected: []string{"explore", "pastoria-city-area"}},
+		{args: []string{"--backend", "graphql", "inspect", "pikachu"}, expected: []string{"inspect", "pikachu"}, backend: "graphql"},
+		{args: []string{"inspect", "--backend=graphql", "pikachu"}, expected: []string{"inspect", "--backend=graphql", "pikachu"}},
+		{args: []string{"--backend=graphql", "sync", "--workers", "2", "move"}, expected: []string{"sync", "--workers", "2", "move"}, backend: "graphql"},
+		{args: []string{"--", "--backend"}, expected: []string{"--backend"}},
+	}
+
+	for _, c := range cases {
+		t.Run(strings.Join(c.args, " "), func(t *testing.T) {
+			flags := flag.NewFlagSet("test", flag.ContinueOnError)
+			backend := flags.String("backend", "", "")
+
+			actual, err := parseArgs(flags, c.args)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(actual, c.expected) {
+				t.Errorf("expected %q, got %q", c.expected, actual)
+			}
+			if *backend != c.backend {
+				t.Errorf("expected backend %q, got %q", c.backend, *backend)
+			}
+		})
+	}
+}
+
+func TestParseArgsUnknownFlag(t *testing.T) {
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+
+	if _, err := parseArgs(flags, []string{"--nope", "map"}); err == nil {
+		t.Errorf("expected an error for an unknown flag")
+	}
+}
+
+func TestRunOneShot(t *testing.T) {
+	cfg := newTestConfig(t)
+
+	status := runOneShot(cfg, []string{"explore", "Pastoria-City-Area"})
+	output := takeOutput(cfg)
+	if status != 0 {
+		t.Errorf("expected status 0, got %d", status)
+	}
+	if !strings.HasPrefix(output, "Exploring pastoria-city-area...\nFound Pokemon:\n") {
+		t.Errorf("unexpected output:\n%s", output)
+	}
+
+	status = runOneShot(cfg, []string{"inspect", "pikachu"})
+	if status != 1 {
+		t.Errorf("expected status 1 for a failed command, got %d", status)
+	}
+}
+
+func TestRunOneShotWithCommandFlags(t *testing.T) {
+	cfg := newTestConfig(t)
+	cfg.aliases["gym"] = "explore canalave-city-area"
+
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	flags.String("backend", "", "")
+
+	args, err := parseArgs(flags, []string{"--backend", "rest", "alias", "--delete", "gym"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	status := runOneShot(cfg, args)
+	if status != 0 {
+		t.Errorf("expected status 0, got %d", status)
+	}
+	if _, ok := cfg.aliases["gym"]; ok {
+		t.Errorf("expected the alias to be deleted, got %v", cfg.aliases)
+	}
+}
@@ -0,0 +1,343 @@
+package main
+
+import (
+	"io"
+	"math/rand"
+	"net/http"
+	"pokedexcli/internal/lineedit"
+	"pokedexcli/internal/pokeapi"
+	"pokedexcli/internal/pokeapitest"
+	"pokedexcli/internal/render"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestConfig(t testing.TB) *config {
+	t.Helper()
+
+	server := pokeapitest.NewServer()
+	t.Cleanup(server.Close)
+
+	client := pokeapi.NewClient(time.Minute, nil)
+	client.SetBaseURL(server.BaseURL())
+
+	commands = newCommands()
+	pokedex = make(map[string]Pokemon)
+
+	return &config{
+		pokeapiClient: client,
+		history:       lineedit.NewHistory(0, false),
+		aliases:       make(map[string]string),
+		macros:        make(map[string][]string),
+		out:           &strings.Builder{},
+		errOut:        &strings.Builder{},
+		rand:          rand.New(rand.NewSource(1)),
+		now:           func() time.Time { return time.Date(2024, 5, 1, 9, 30, 0, 0, time.UTC) },
+		output:        render.Text,
+	}
+}
+
+// takeOutput returns what commands have written to cfg.out since it was
+// last taken.
+func takeOutput(cfg *config) string {
+	return take(cfg.out)
+}
+
+// takeStderr returns what has been written to cfg.errOut since it was last
+// taken.
+func takeStderr(cfg *config) string {
+	return take(cfg.errOut)
+}
+
+func take(w io.Writer) string {
+	b := w.(*strings.Builder)
+	defer b.Reset()
+	return b.String()
+}
+
+func TestCommandMapPagination(t *testing.T) {
+	cfg := newTestConfig(t)
+
+	if err := runCommand(cfg, []string{"map"}); err != nil {
+		t.Fatal(err)
+	}
+	output := takeOutput(cfg)
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if len(lines) != 20 || lines[0] != "canalave-city-area" {
+		t.Fatalf("unexpected first page:\n%s", output)
+	}
+	if cfg.previous != "" {
+		t.Errorf("expected no previous page, got %s", cfg.previous)
+	}
+
+	if err := runCommand(cfg, []string{"map"}); err != nil {
+		t.Fatal(err)
+	}
+	output = takeOutput(cfg)
+	if !strings.HasPrefix(output, "mt-coronet-1f-route-216\n") {
+		t.Errorf("unexpected second page:\n%s", output)
+	}
+	if cfg.previous == "" {
+		t.Errorf("expected a previous page after paging forward")
+	}
+
+	if err := runCommand(cfg, []string{"mapb"}); err != nil {
+		t.Fatal(err)
+	}
+	output = takeOutput(cfg)
+	if !strings.HasPrefix(output, "canalave-city-area\n") {
+		t.Errorf("expected mapb to return to the first page:\n%s", output)
+	}
+
+	if err := runCommand(cfg, []string{"mapb"}); err != nil {
+		t.Fatal(err)
+	}
+	output = takeOutput(cfg)
+	if output != "you're on the first page\n" {
+		t.Errorf("unexpected output on first page: %q", output)
+	}
+}
+
+func TestCommandExplore(t *testing.T) {
+	cases := []struct {
+		name     string
+		args     []string
+		expected []string
+		err      string
+	}{
+		{
+			name:     "pastoria-city-area",
+			args:     []string{"pastoria-city-area"},
+			expected: []string{"Found Pokemon:", " - tentacool", " - gastrodon"},
+		},
+		{
+			name:     "mt-coronet-2f",
+			args:     []string{"mt-coronet-2f"},
+			expected: []string{"No Pokemon found in this location"},
+		},
+		{
+			name: "nowhere-area",
+			args: []string{"nowhere-area"},
+			err:  "location nowhere-area not found",
+		},
+		{
+			name:     "quoted multi-word name",
+			args:     []string{"Pastoria City Area"},
+			expected: []string{"Exploring pastoria-city-area...", " - tentacool"},
+		},
+		{
+			name: "missing name",
+			err:  "not enough arguments (usage: explore <location>)",
+		},
+		{
+			name: "extra arguments",
+			args: []string{"pastoria-city-area", "canalave-city-area"},
+			err:  "too many arguments (usage: explore <location>)",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cfg := newTestConfig(t)
+
+			err := runCommand(cfg, append([]string{"explore"}, c.args...))
+			output := takeOutput(cfg)
+			if c.err != "" {
+				if err == nil || err.Error() != c.err {
+					t.Errorf("expected error %q, got %v", c.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, expected := range c.expected {
+				if !strings.Contains(output, expected+"\n") {
+					t.Errorf("expected output to contain %q, got:\n%s", expected, output)
+				}
+			}
+		})
+	}
+}
+
+func TestCommandCatchAndInspect(t *testing.T) {
+	cfg := newTestConfig(t)
+	args := commandArgs{positional: []string{"pikachu"}}
+
+	// Catching is random, so keep throwing until it succeeds.
+	for i := 0; i < 100; i++ {
+		if _, err := commandCatch(cfg, args); err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := pokedex["pikachu"]; ok {
+			break
+		}
+	}
+
+	pikachu, ok := pokedex["pikachu"]
+	if !ok {
+		t.Fatalf("expected pikachu to be caught")
+	}
+	if pikachu.height != 4 || pikachu.weight != 60 || pikachu.stats["speed"] != 90 {
+		t.Errorf("unexpected pokedex entry: %+v", pikachu)
+	}
+
+	res, err := commandInspect(cfg, args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	inspected := res.(*recordList[pokemonRecord]).records
+	if len(inspected) != 1 || inspected[0].Genus != "Mouse Pokémon" || inspected[0].Stats["speed"] != 90 {
+		t.Errorf("unexpected inspect result: %+v", inspected)
+	}
+
+	if err := runCommand(cfg, []string{"inspect", "pikachu"}); err != nil {
+		t.Fatal(err)
+	}
+	output := takeOutput(cfg)
+	for _, expected := range []string{
+		"Name: pikachu\n",
+		"Height: 4\n",
+		" - electric\n",
+		"Species: pikachu, the Mouse Pokémon (generation-i)\n",
+		"Evolutions: pichu -> pikachu -> raichu\n",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("expected inspect output to contain %q, got:\n%s", expected, output)
+		}
+	}
+
+	res, err = commandPokedex(cfg, commandArgs{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	caught := res.(*recordList[pokedexRecord]).records
+	if len(caught) != 1 || caught[0].Name != "pikachu" || caught[0].Types[0] != "electric" {
+		t.Errorf("unexpected pokedex result: %+v", caught)
+	}
+
+	if err := runCommand(cfg, []string{"pokedex"}); err != nil {
+		t.Fatal(err)
+	}
+	output = takeOutput(cfg)
+	if output != "Your Pokedex:\n - pikachu\n" {
+		t.Errorf("unexpected pokedex output: %q", output)
+	}
+}
+
+// BenchmarkCommandCatch measures a whole catch, from the request to the
+// fixture server through decoding to the roll, with the response fetched
+// each time and with it in the in-memory cache.
+func BenchmarkCommandCatch(b *testing.B) {
+	cfg := newTestConfig(b)
+	cfg.out = io.Discard
+	args := commandArgs{positional: []string{"pikachu"}}
+
+	b.Run("fetched", func(b *testing.B) {
+		server := pokeapitest.NewServer()
+		defer server.Close()
+		b.ReportAllocs()
+
+		for i := 0; i < b.N; i++ {
+			client := pokeapi.NewClient(time.Minute, nil)
+			client.SetBaseURL(server.BaseURL())
+			cfg.pokeapiClient = client
+			if _, err := commandCatch(cfg, args); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("cached", func(b *testing.B) {
+		b.ReportAllocs()
+
+		for i := 0; i < b.N; i++ {
+			if _, err := commandCatch(cfg, args); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func TestCommandCatchUnknownPokemon(t *testing.T) {
+	cfg := newTestConfig(t)
+	args := commandArgs{positional: []string{"missingno"}}
+
+	_, err := commandCatch(cfg, args)
+	if err == nil || err.Error() != "Pokemon missingno not found" {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if len(pokedex) != 0 {
+		t.Errorf("expected nothing to be caught")
+	}
+}
+
+func TestCommandInspectOffline(t *testing.T) {
+	cfg := newTestConfig(t)
+	client := pokeapi.NewClient(time.Minute, nil)
+	client.SetBaseURL("http://127.0.0.1:1")
+	cfg.pokeapiClient = client
+	pokedex["pikachu"] = Pokemon{name: "pikachu", height: 4, weight: 60, types: []string{"electric"}, stats: map[string]int{"speed": 90}}
+
+	if err := runCommand(cfg, []string{"inspect", "pikachu"}); err != nil {
+		t.Fatal(err)
+	}
+	output := takeOutput(cfg)
+	warning := takeStderr(cfg)
+	expected := "Name: pikachu\nHeight: 4\nWeight: 60\nStats:\n -speed: 90\nTypes:\n - electric\n"
+	if output != expected {
+		t.Errorf("expected %q, got %q", expected, output)
+	}
+	if !strings.HasPrefix(warning, "Warning: leaving out the species and evolutions of pikachu") {
+		t.Errorf("expected a warning about the missing profile, got %q", warning)
+	}
+}
+
+func TestCommandInspectProfileErrors(t *testing.T) {
+	cfg := newTestConfig(t)
+	server := pokeapitest.NewServer()
+	t.Cleanup(server.Close)
+	client := pokeapi.NewClient(time.Minute, nil)
+	client.SetBaseURL(server.BaseURL())
+	cfg.pokeapiClient = client
+	pokedex["pikachu"] = Pokemon{name: "pikachu", height: 4, weight: 60, types: []string{"electric"}}
+
+	server.SetFault("/pokemon-species/pikachu", http.StatusInternalServerError)
+	if err := runCommand(cfg, []string{"inspect", "pikachu"}); err == nil {
+		t.Errorf("expected a server error to be reported")
+	}
+	takeOutput(cfg)
+
+	server.ClearFaults()
+	server.SetLatency(50 * time.Millisecond)
+	timeout := profileTimeout
+	profileTimeout = 20 * time.Millisecond
+	t.Cleanup(func() { profileTimeout = timeout })
+
+	if err := runCommand(cfg, []string{"inspect", "pikachu"}); err != nil {
+		t.Errorf("expected a timeout to leave out the profile, got %v", err)
+	}
+	output := takeOutput(cfg)
+	warning := takeStderr(cfg)
+	if !strings.HasPrefix(output, "Name: pikachu\n") || strings.Contains(output, "Species:") {
+		t.Errorf("expected only the Pokedex fields, got %q", output)
+	}
+	if !strings.HasPrefix(warning, "Warning: ") {
+		t.Errorf("expected a warning about the timeout, got %q", warning)
+	}
+}
+
+func TestCommandInspectUncaught(t *testing.T) {
+	cfg := newTestConfig(t)
+	args := commandArgs{positional: []string{"pikachu"}}
+
+	if _, err := commandInspect(cfg, args); err == nil || err.Error() != "you have not caught pikachu" {
+		t.Errorf("unexpected error: %v", err)
+	}
+	output := takeOutput(cfg)
+	if output != "" {
+		t.Errorf("expected no output, got %q", output)
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"pokedexcli/internal/lineedit"
+	"pokedexcli/internal/pokeapi"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// completer completes command names, also after ";" and "&&", filter names
+// after "|", and the first argument of commands that take a name: caught
+// Pokemon for inspect, the last map page for explore, the last explored
+// location's Pokemon for catch and resource lists for sync.
+func completer(cfg *config) lineedit.Completer {
+	return func(line []rune, pos int) ([]string, int) {
+		start := pos
+		for start > 0 && !unicode.IsSpace(line[start-1]) {
+			start--
+		}
+
+		before := string(line[:start])
+		prefix := strings.ToLower(string(line[start:pos]))
+
+		if i := max(strings.LastIndex(before, ";"), strings.LastIndex(before, "&&")); i >= 0 && i > strings.LastIndex(before, "|") {
+			before = strings.TrimLeft(before[i+1:], "&")
+		}
+
+		var options []string
+		if i := strings.LastIndex(before, "|"); i >= 0 {
+			if len(cleanInput(before[i+1:])) == 0 {
+				for _, filter := range pipelineFilters {
+					options = append(options, filter.name)
+				}
+			}
+			return matchCompletions(options, prefix), start
+		}
+
+		words := cleanInput(before)
+		switch len(words) {
+		case 0:
+			for name := range commands {
+				options = append(options, name)
+			}
+			for name := range cfg.aliases {
+				options = append(options, name)
+			}
+			for name := range cfg.macros {
+				options = append(options, name)
+			}
+		case 1:
+			switch words[0] {
+			case "inspect":
+				for name := range pokedex {
+					options = append(options, name)
+				}
+			case "explore":
+				options = cfg.locations
+			case "catch":
+				options = cfg.encounters
+			case "help":
+				for name := range commands {
+					options = append(options, name)
+				}
+			case "set":
+				for _, setting := range sessionSettings {
+					options = append(options, setting.name)
+				}
+			case "sync":
+				options = pokeapi.SyncResources
+			case "profile":
+				options = []string{"list", "new", "switch", "delete"}
+			}
+		}
+
+		return matchCompletions(options, prefix), start
+	}
+}
+
+// matchCompletions returns the options that start with prefix, sorted and
+// without duplicates.
+func matchCompletions(options []string, prefix string) []string {
+	var candidates []string
+	seen := make(map[string]bool)
+	for _, option := range options {
+		if strings.HasPrefix(option, prefix) && !seen[option] {
+			seen[option] = true
+			candidates = append(candidates, option)
+		}
+	}
+	sort.Strings(candidates)
+
+	return candidates
+}
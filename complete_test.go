@@ -0,0 +1,56 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCompleter(t *testing.T) {
+	commands = map[string]cliCommand{
+		"map":     {name: "map"},
+		"mapb":    {name: "mapb"},
+		"explore": {name: "explore"},
+		"catch":   {name: "catch"},
+		"inspect": {name: "inspect"},
+	}
+	pokedex = map[string]Pokemon{
+		"pikachu": {name: "pikachu"},
+		"pidgey":  {name: "pidgey"},
+		"psyduck": {name: "psyduck"},
+	}
+	cfg := &config{
+		locations:  []string{"canalave-city-area", "eterna-city-area", "eterna-forest-area"},
+		encounters: []string{"tentacool", "tentacruel", "magikarp", "tentacool"},
+	}
+	complete := completer(cfg)
+
+	cases := []struct {
+		line       string
+		candidates []string
+		start      int
+	}{
+		{line: "ma", candidates: []string{"map", "mapb"}, start: 0},
+		{line: "", candidates: []string{"catch", "explore", "inspect", "map", "mapb"}, start: 0},
+		{line: "inspect pi", candidates: []string{"pidgey", "pikachu"}, start: 8},
+		{line: "explore eterna-", candidates: []string{"eterna-city-area", "eterna-forest-area"}, start: 8},
+		{line: "catch tenta", candidates: []string{"tentacool", "tentacruel"}, start: 6},
+		{line: "  CATCH  Mag", candidates: []string{"magikarp"}, start: 9},
+		{line: "map pi", candidates: nil, start: 4},
+		{line: "catch tentacool x", candidates: nil, start: 16},
+		{line: "pokedex | wh", candidates: []string{"where"}, start: 10},
+		{line: "map | grep c", candidates: nil, start: 11},
+		{line: "map; insp", candidates: []string{"inspect"}, start: 5},
+		{line: "map | count && catch tenta", candidates: []string{"tentacool", "tentacruel"}, start: 21},
+		{line: "map; pokedex | co", candidates: []string{"count"}, start: 15},
+	}
+
+	for _, c := range cases {
+		t.Run(c.line, func(t *testing.T) {
+			line := []rune(c.line)
+			candidates, start := complete(line, len(line))
+			if !reflect.DeepEqual(candidates, c.candidates) || start != c.start {
+				t.Errorf("expected %v at %d, got %v at %d", c.candidates, c.start, candidates, start)
+			}
+		})
+	}
+}
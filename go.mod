@@ -4,5 +4,12 @@ go 1.22.5
 
 require (
 	github.com/eiannone/keyboard v0.0.0-20220611211555-0d226195f203
-	golang.org/x/sys v0.0.0-20220520151302-bc2c85ada10a // indirect
+	github.com/mattn/go-runewidth v0.0.16
+	golang.org/x/term v0.25.0
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require (
+	github.com/rivo/uniseg v0.2.0 // indirect
+	golang.org/x/sys v0.26.0 // indirect
 )
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Command groups, in the order help lists them.
+const (
+	groupExploring = "Exploring"
+	groupPokemon   = "Pokemon"
+	groupOffline   = "Offline data"
+	groupGeneral   = "General"
+)
+
+var commandGroups = []string{groupExploring, groupPokemon, groupOffline, groupGeneral}
+
+// lookupCommand finds a command by name or alias, ignoring case.
+func lookupCommand(name string) (cliCommand, bool) {
+	name = strings.ToLower(name)
+
+	if command, ok := commands[name]; ok {
+		return command, true
+	}
+	for _, command := range commands {
+		for _, alias := range command.aliases {
+			if alias == name {
+				return command, true
+			}
+		}
+	}
+
+	return cliCommand{}, false
+}
+
+func commandHelp(cfg *config, args commandArgs) (result, error) {
+	var b strings.Builder
+
+	if name := args.arg(0); name != "" {
+		command, ok := lookupCommand(name)
+		filter, isFilter := lookupFilter(name)
+		switch {
+		case ok:
+			printCommandHelp(&b, command)
+		case cfg.isUserCommand(name):
+			printUserCommandHelp(&b, cfg, strings.ToLower(name))
+		case isFilter:
+			printCommandHelp(&b, filter.command())
+		default:
+			return nil, unknownCommandError(name)
+		}
+		return newTextResult(b.String()), nil
+	}
+
+	fmt.Fprintln(&b, "Welcome to the Pokedex!")
+	fmt.Fprintln(&b, "Usage: <command> [args...] [| <filter> [args...]]...")
+	fmt.Fprintln(&b, "Separate commands with ; to run each, or && to stop at the first error.")
+	fmt.Fprintln(&b, "End a line with \\ to continue it on the next one.")
+	fmt.Fprintln(&b, "Run \"help <command>\" for details about a command.")
+	printCommandList(&b)
+	printFilterList(&b)
+
+	return newTextResult(b.String()), nil
+}
+
+// printCommandList writes every command's synopsis and description, grouped
+// and sorted by name.
+func printCommandList(w io.Writer) {
+	width := 0
+	groups := make(map[string][]cliCommand)
+	for _, command := range commands {
+		groups[command.group] = append(groups[command.group], command)
+		width = max(width, len(command.synopsis()))
+	}
+
+	for _, group := range commandGroups {
+		list := groups[group]
+		if len(list) == 0 {
+			continue
+		}
+		sort.Slice(list, func(i, j int) bool { return list[i].name < list[j].name })
+
+		fmt.Fprintf(w, "\n%s:\n", group)
+		for _, command := range list {
+			fmt.Fprintf(w, "  %-*s  %s\n", width, command.synopsis(), command.description)
+		}
+	}
+}
+
+// printCommandHelp writes the usage, flags, aliases and examples of one
+// command.
+func printCommandHelp(w io.Writer, command cliCommand) {
+	fmt.Fprintf(w, "Usage: %s\n\n", command.synopsis())
+	fmt.Fprintf(w, "%s\n", command.description)
+
+	if len(command.aliases) > 0 {
+		fmt.Fprintf(w, "\nAliases: %s\n", strings.Join(command.aliases, ", "))
+	}
+
+	if command.flags != nil {
+		fmt.Fprintln(w, "\nFlags:")
+		command.flagSet().VisitAll(func(f *flag.Flag) {
+			kind, usage := flag.UnquoteUsage(f)
+			fmt.Fprintf(w, "  --%s %s\n", f.Name, kind)
+			fmt.Fprintf(w, "        %s (default %s)\n", usage, f.DefValue)
+		})
+	}
+
+	if len(command.examples) > 0 {
+		fmt.Fprintln(w, "\nExamples:")
+		for _, example := range command.examples {
+			fmt.Fprintf(w, "  %s\n", example)
+		}
+	}
+}
+
+// printUserCommandHelp writes what an alias or macro runs.
+func printUserCommandHelp(w io.Writer, cfg *config, name string) {
+	if expansion, ok := cfg.aliases[name]; ok {
+		fmt.Fprintf(w, "%s is an alias for: %s\n", name, expansion)
+		return
+	}
+
+	fmt.Fprintf(w, "%s is a macro that runs:\n", name)
+	for _, step := range cfg.macros[name] {
+		fmt.Fprintf(w, "  %s\n", step)
+	}
+}
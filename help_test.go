@@ -0,0 +1,115 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCommandHelp(t *testing.T) {
+	cases := []struct {
+		name     string
+		args     []string
+		expected []string
+		err      string
+	}{
+		{
+			name: "grouped list",
+			expected: []string{
+				"\nExploring:\n  explore <location> ",
+				"  map ",
+				"\nGeneral:\n  alias ",
+				"  exit ",
+				"  help [command] ",
+			},
+		},
+		{
+			name: "one command",
+			args: []string{"sync"},
+			expected: []string{
+				"Usage: sync [flags] [resource...]\n",
+				"  --workers int\n        number of parallel downloads (default 8)\n",
+				"Examples:\n  sync\n",
+			},
+		},
+		{
+			name:     "by alias",
+			args:     []string{"Q"},
+			expected: []string{"Usage: exit\n", "Aliases: q, quit\n"},
+		},
+		{
+			name: "unknown command",
+			args: []string{"fly"},
+			err:  `unknown command "fly"`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cfg := newTestConfig(t)
+
+			err := runCommand(cfg, append([]string{"help"}, c.args...))
+			output := takeOutput(cfg)
+			if c.err != "" {
+				if err == nil || err.Error() != c.err {
+					t.Errorf("expected error %q, got %v", c.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, expected := range c.expected {
+				if !strings.Contains(output, expected) {
+					t.Errorf("expected output to contain %q, got:\n%s", expected, output)
+				}
+			}
+		})
+	}
+}
+
+func TestCommandHelpOrder(t *testing.T) {
+	cfg := newTestConfig(t)
+
+	if err := runCommand(cfg, []string{"help"}); err != nil {
+		t.Fatal(err)
+	}
+	output := takeOutput(cfg)
+
+	// Groups keep their fixed order and commands are sorted within them.
+	last := -1
+	for _, name := range []string{"Exploring:", "  explore", "  map", "  mapb", "Pokemon:", "  catch", "General:", "  exit", "  help", "  history"} {
+		i := strings.Index(output, name)
+		if i <= last {
+			t.Fatalf("expected %q after position %d, got %d:\n%s", name, last, i, output)
+		}
+		last = i
+	}
+}
+
+func TestCommandAliases(t *testing.T) {
+	seen := make(map[string]string)
+	for name, command := range newCommands() {
+		if command.name != name {
+			t.Errorf("command %q registered as %q", command.name, name)
+		}
+		seen[name] = name
+	}
+	for name, command := range newCommands() {
+		for _, alias := range command.aliases {
+			if other, ok := seen[alias]; ok {
+				t.Errorf("alias %q of %s is already used by %s", alias, name, other)
+			}
+			seen[alias] = name
+		}
+	}
+
+	cfg := newTestConfig(t)
+	if err := runCommand(cfg, []string{"dex"}); err != nil {
+		t.Fatal(err)
+	}
+	output := takeOutput(cfg)
+	if output != "Your Pokedex:\n" {
+		t.Errorf("unexpected output for dex: %q", output)
+	}
+}
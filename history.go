@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+	"pokedexcli/internal/lineedit"
+	"strconv"
+	"strings"
+)
+
+func commandHistory(cfg *config, args commandArgs) (result, error) {
+	lines := textResult{}
+	numbers := cfg.history.Numbers()
+	for i, entry := range cfg.history.Entries() {
+		lines = append(lines, fmt.Sprintf("%5d  %s", numbers[i], entry))
+	}
+
+	return lines, nil
+}
+
+// expandHistory replaces "!N" with the history entry history shows as
+// number N and "!!" with the last entry. It reports whether input was
+// expanded.
+func expandHistory(input string, history *lineedit.History) (string, bool, error) {
+	input = strings.TrimSpace(input)
+	if !strings.HasPrefix(input, "!") {
+		return input, false, nil
+	}
+
+	entries := history.Entries()
+	if input == "!!" {
+		if len(entries) == 0 {
+			return "", false, fmt.Errorf("!!: event not found")
+		}
+		return entries[len(entries)-1], true, nil
+	}
+
+	n, err := strconv.Atoi(input[1:])
+	if err != nil {
+		return "", false, fmt.Errorf("%s: event not found", input)
+	}
+	entry, ok := history.Lookup(n)
+	if !ok {
+		return "", false, fmt.Errorf("%s: event not found", input)
+	}
+
+	return entry, true, nil
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"pokedexcli/internal/lineedit"
+	"strings"
+	"testing"
+)
+
+func TestExpandHistory(t *testing.T) {
+	history := lineedit.NewHistory(0, false)
+	for _, line := range []string{"map", "explore pastoria-city-area", "catch tentacool"} {
+		history.Add(line)
+	}
+
+	cases := []struct {
+		input    string
+		expected string
+		expanded bool
+		err      bool
+	}{
+		{input: "mapb", expected: "mapb"},
+		{input: "!2", expected: "explore pastoria-city-area", expanded: true},
+		{input: " !1 ", expected: "map", expanded: true},
+		{input: "!!", expected: "catch tentacool", expanded: true},
+		{input: "!4", err: true},
+		{input: "!0", err: true},
+		{input: "!x", err: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.input, func(t *testing.T) {
+			actual, expanded, err := expandHistory(c.input, history)
+			if (err != nil) != c.err {
+				t.Fatalf("expected error %v, got %v", c.err, err)
+			}
+			if actual != c.expected || expanded != c.expanded {
+				t.Errorf("expected %q (expanded %v), got %q (expanded %v)", c.expected, c.expanded, actual, expanded)
+			}
+		})
+	}
+}
+
+func TestCommandHistory(t *testing.T) {
+	cfg := &config{history: lineedit.NewHistory(0, false)}
+	cfg.history.Add("map")
+	cfg.history.Add("mapb")
+
+	res, err := commandHistory(cfg, commandArgs{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var b strings.Builder
+	res.writeText(&b)
+	if b.String() != "    1  map\n    2  mapb\n" {
+		t.Errorf("unexpected output: %q", b.String())
+	}
+}
+
+func TestHistoryNumbersAreStable(t *testing.T) {
+	cfg := &config{history: lineedit.NewHistory(0, true)}
+	for _, line := range []string{"map", "history", "explore pastoria-city-area", "history"} {
+		cfg.history.Add(line)
+	}
+
+	res, err := commandHistory(cfg, commandArgs{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var b strings.Builder
+	res.writeText(&b)
+	if b.String() != "    1  map\n    3  explore pastoria-city-area\n    4  history\n" {
+		t.Errorf("unexpected output: %q", b.String())
+	}
+
+	cfg.history.Add("map")
+	if line, _, err := expandHistory("!3", cfg.history); err != nil || line != "explore pastoria-city-area" {
+		t.Errorf("expected !3 to stay the entry history showed, got %q, %v", line, err)
+	}
+	if _, _, err := expandHistory("!1", cfg.history); err == nil {
+		t.Errorf("expected !1 to be gone once map was added again")
+	}
+}
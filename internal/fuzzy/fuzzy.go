@@ -0,0 +1,61 @@
+// Package fuzzy finds the closest match for a misspelt word.
+package fuzzy
+
+// Distance returns the Damerau-Levenshtein distance between a and b: the
+// number of insertions, deletions, substitutions and transpositions of
+// adjacent characters needed to turn one into the other. It uses the
+// optimal string alignment variant, so no substring is edited twice.
+func Distance(a, b string) int {
+	s, t := []rune(a), []rune(b)
+
+	// Three rows of the edit matrix are enough: transpositions look two
+	// rows back.
+	prev2 := make([]int, len(t)+1)
+	prev := make([]int, len(t)+1)
+	cur := make([]int, len(t)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(s); i++ {
+		cur[0] = i
+		for j := 1; j <= len(t); j++ {
+			cost := 1
+			if s[i-1] == t[j-1] {
+				cost = 0
+			}
+
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && s[i-1] == t[j-2] && s[i-2] == t[j-1] {
+				cur[j] = min(cur[j], prev2[j-2]+1)
+			}
+		}
+		prev2, prev, cur = prev, cur, prev2
+	}
+
+	return prev[len(t)]
+}
+
+// MaxDistance is how far a word may be from a suggestion: about one edit
+// for every three characters, up to three, so short words only match near
+// misses and single characters never match.
+func MaxDistance(word string) int {
+	return min(3, (len([]rune(word))+1)/3)
+}
+
+// Suggest returns the candidate closest to word, if one is within
+// MaxDistance(word). Ties go to the candidate that sorts first, so the
+// result does not depend on the order of candidates.
+func Suggest(word string, candidates []string) (string, bool) {
+	limit := MaxDistance(word)
+
+	best, bestDistance := "", limit+1
+	for _, candidate := range candidates {
+		d := Distance(word, candidate)
+		if d < bestDistance || (d == bestDistance && candidate < best) {
+			best, bestDistance = candidate, d
+		}
+	}
+
+	return best, bestDistance <= limit
+}
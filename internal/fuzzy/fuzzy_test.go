@@ -0,0 +1,63 @@
+package fuzzy
+
+import "testing"
+
+func TestDistance(t *testing.T) {
+	cases := []struct {
+		a, b     string
+		expected int
+	}{
+		{"", "", 0},
+		{"map", "map", 0},
+		{"", "map", 3},
+		{"mapb", "map", 1},
+		{"mpa", "map", 1},
+		{"charmandr", "charmander", 1},
+		{"pikachu", "pichu", 2},
+		{"kitten", "sitting", 3},
+		{"ca", "abc", 3},
+		{"pokémon", "pokemon", 1},
+	}
+
+	for _, c := range cases {
+		if actual := Distance(c.a, c.b); actual != c.expected {
+			t.Errorf("Distance(%q, %q): expected %d, got %d", c.a, c.b, c.expected, actual)
+		}
+		if actual := Distance(c.b, c.a); actual != c.expected {
+			t.Errorf("Distance(%q, %q): expected %d, got %d", c.b, c.a, c.expected, actual)
+		}
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	candidates := []string{"bulbasaur", "charmander", "charmeleon", "squirtle", "pikachu", "pichu"}
+
+	cases := []struct {
+		word     string
+		expected string
+		ok       bool
+	}{
+		{word: "charmandr", expected: "charmander", ok: true},
+		{word: "pickachu", expected: "pikachu", ok: true},
+		{word: "sqiurtle", expected: "squirtle", ok: true},
+		{word: "pichu", expected: "pichu", ok: true},
+		{word: "mewtwo"},
+		{word: "p"},
+	}
+
+	for _, c := range cases {
+		actual, ok := Suggest(c.word, candidates)
+		if ok != c.ok || (ok && actual != c.expected) {
+			t.Errorf("Suggest(%q): expected %q, %t, got %q, %t", c.word, c.expected, c.ok, actual, ok)
+		}
+	}
+}
+
+func TestSuggestTies(t *testing.T) {
+	// "mab" is one edit from both; the result must not depend on order.
+	for _, candidates := range [][]string{{"map", "mad"}, {"mad", "map"}} {
+		if actual, _ := Suggest("mab", candidates); actual != "mad" {
+			t.Errorf("Suggest(%q, %q): expected mad, got %q", "mab", candidates, actual)
+		}
+	}
+}
@@ -0,0 +1,485 @@
+// Package lineedit is a small line editor for raw-mode terminals. It keeps
+// the line being typed, applies editing keys to it and redraws it, including
+// lines that wrap across several terminal rows or contain wide characters.
+package lineedit
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+
+	"github.com/mattn/go-runewidth"
+)
+
+type Key int
+
+const (
+	KeyRune Key = iota // a printable character, in Event.Rune
+	KeyEnter
+	KeyBackspace
+	KeyDelete
+	KeyLeft
+	KeyRight
+	KeyUp
+	KeyDown
+	KeyHome
+	KeyEnd
+	KeyCtrlA
+	KeyCtrlE
+	KeyCtrlK
+	KeyCtrlU
+	KeyCtrlW
+	KeyTab
+	KeyCtrlR
+	KeyCtrlG
+	KeyEsc
+	KeyPageUp
+	KeyPageDown
+	KeyCtrlC
+	KeyCtrlD
+	// KeyResize reports that the terminal changed size. Call SetWidth with
+	// the new width before handling it.
+	KeyResize
+)
+
+type Event struct {
+	Key  Key
+	Rune rune
+}
+
+// Completer returns the candidates for the word that ends at pos in line,
+// and the index in line where that word starts.
+type Completer func(line []rune, pos int) (candidates []string, start int)
+
+const defaultWidth = 80
+
+type Editor struct {
+	out    io.Writer
+	prompt string
+	width  int
+
+	buf []rune
+	pos int
+
+	history      *History
+	historyIndex int
+	draft        []rune
+
+	// search is set while a Ctrl-R reverse search is in progress.
+	search *search
+
+	completer Completer
+	lastKey   Key
+
+	// cursorRow is the terminal row, relative to the prompt's row, that the
+	// cursor was left on by the last redraw.
+	cursorRow int
+}
+
+type search struct {
+	query    []rune
+	match    int // index into history entries, or -1 when nothing matches
+	original []rune
+}
+
+func New(out io.Writer, prompt string) *Editor {
+	return &Editor{
+		out:     out,
+		prompt:  prompt,
+		width:   defaultWidth,
+		history: NewHistory(0, false),
+	}
+}
+
+// SetWidth tells the editor how many columns the terminal has.
+func (e *Editor) SetWidth(width int) {
+	if width <= 0 {
+		width = defaultWidth
+	}
+	e.width = width
+}
+
+// Start begins a new, empty line and prints the prompt.
+func (e *Editor) Start() {
+	e.buf = nil
+	e.pos = 0
+	e.historyIndex = e.history.Len()
+	e.draft = nil
+	e.search = nil
+	e.cursorRow = 0
+	e.refresh()
+}
+
+// Line returns the text typed so far.
+func (e *Editor) Line() string {
+	return string(e.buf)
+}
+
+// Cursor returns the cursor position as an index into Line's runes.
+func (e *Editor) Cursor() int {
+	return e.pos
+}
+
+// SetPrompt changes the prompt shown from the next Start. The prompt may
+// contain ANSI color sequences.
+func (e *Editor) SetPrompt(prompt string) {
+	e.prompt = prompt
+}
+
+// SetCompleter sets the function Tab uses to complete words.
+func (e *Editor) SetCompleter(completer Completer) {
+	e.completer = completer
+}
+
+// SetHistory replaces the history that Up, Down and Ctrl-R search.
+func (e *Editor) SetHistory(history *History) {
+	e.history = history
+	e.historyIndex = history.Len()
+}
+
+// AddHistory appends a line for Up, Down and Ctrl-R to recall.
+func (e *Editor) AddHistory(line string) {
+	e.history.Add(line)
+	e.historyIndex = e.history.Len()
+}
+
+// Handle applies a key to the line. When the key is Enter it returns the
+// finished line and true.
+func (e *Editor) Handle(ev Event) (string, bool) {
+	lastKey := e.lastKey
+	e.lastKey = ev.Key
+
+	if e.search != nil && e.handleSearch(ev) {
+		e.refresh()
+		return "", false
+	}
+
+	switch ev.Key {
+	case KeyEnter:
+		e.pos = len(e.buf)
+		e.refresh()
+		fmt.Fprint(e.out, "\r\n")
+		return string(e.buf), true
+	case KeyRune:
+		e.insert(ev.Rune)
+	case KeyBackspace:
+		if e.pos > 0 {
+			e.buf = append(e.buf[:e.pos-1], e.buf[e.pos:]...)
+			e.pos--
+		}
+	case KeyDelete, KeyCtrlD:
+		if e.pos < len(e.buf) {
+			e.buf = append(e.buf[:e.pos], e.buf[e.pos+1:]...)
+		}
+	case KeyLeft:
+		if e.pos > 0 {
+			e.pos--
+		}
+	case KeyRight:
+		if e.pos < len(e.buf) {
+			e.pos++
+		}
+	case KeyHome, KeyCtrlA:
+		e.pos = 0
+	case KeyEnd, KeyCtrlE:
+		e.pos = len(e.buf)
+	case KeyCtrlK:
+		e.buf = e.buf[:e.pos]
+	case KeyCtrlU:
+		e.buf = e.buf[e.pos:]
+		e.pos = 0
+	case KeyCtrlW:
+		start := e.pos
+		for start > 0 && unicode.IsSpace(e.buf[start-1]) {
+			start--
+		}
+		for start > 0 && !unicode.IsSpace(e.buf[start-1]) {
+			start--
+		}
+		e.buf = append(e.buf[:start], e.buf[e.pos:]...)
+		e.pos = start
+	case KeyUp:
+		e.recall(e.historyIndex - 1)
+	case KeyDown:
+		e.recall(e.historyIndex + 1)
+	case KeyTab:
+		e.complete(lastKey == KeyTab)
+	case KeyCtrlR:
+		e.search = &search{match: -1, original: e.buf}
+	case KeyCtrlC:
+		e.pos = len(e.buf)
+		e.refresh()
+		fmt.Fprint(e.out, "^C\r\n")
+		e.Start()
+		return "", false
+	case KeyResize:
+		// The terminal has rewrapped the line to the new width, so the
+		// cursor is no longer on the row the last redraw left it on.
+		text := append([]rune(e.displayPrompt()), e.buf...)
+		e.cursorRow = layout(text, e.width)[len(text)-len(e.buf)+e.pos].row
+	default:
+		return "", false
+	}
+
+	e.refresh()
+	return "", false
+}
+
+func (e *Editor) insert(r rune) {
+	if !unicode.IsPrint(r) {
+		return
+	}
+
+	e.buf = append(e.buf, 0)
+	copy(e.buf[e.pos+1:], e.buf[e.pos:])
+	e.buf[e.pos] = r
+	e.pos++
+}
+
+// complete replaces the word before the cursor with its only candidate, or
+// with the prefix all candidates share. When that doesn't change anything and
+// Tab was pressed twice in a row, the candidates are listed below the line.
+func (e *Editor) complete(list bool) {
+	if e.completer == nil {
+		return
+	}
+
+	candidates, start := e.completer(e.buf, e.pos)
+	if len(candidates) == 0 || start < 0 || start > e.pos {
+		return
+	}
+
+	replacement := candidates[0]
+	if len(candidates) == 1 {
+		replacement += " "
+	} else {
+		for _, c := range candidates[1:] {
+			replacement = commonPrefix(replacement, c)
+		}
+	}
+
+	word := string(e.buf[start:e.pos])
+	if len(candidates) > 1 && replacement == word {
+		if list {
+			e.listCandidates(candidates)
+		}
+		return
+	}
+
+	rest := append([]rune(replacement), e.buf[e.pos:]...)
+	e.buf = append(e.buf[:start:start], rest...)
+	e.pos = start + len([]rune(replacement))
+}
+
+// listCandidates prints candidates in columns under the line. The line is
+// redrawn below them by the caller.
+func (e *Editor) listCandidates(candidates []string) {
+	var b strings.Builder
+
+	positions := layout(append([]rune(e.prompt), e.buf...), e.width)
+	if down := positions[len(positions)-1].row - e.cursorRow; down > 0 {
+		fmt.Fprintf(&b, "\x1b[%dB", down)
+	}
+	b.WriteString("\r\n")
+
+	colWidth := 0
+	for _, c := range candidates {
+		colWidth = max(colWidth, runewidth.StringWidth(c)+2)
+	}
+	columns := max(e.width/colWidth, 1)
+
+	for i, c := range candidates {
+		b.WriteString(c)
+		if (i+1)%columns == 0 || i == len(candidates)-1 {
+			b.WriteString("\r\n")
+		} else {
+			b.WriteString(strings.Repeat(" ", colWidth-runewidth.StringWidth(c)))
+		}
+	}
+
+	io.WriteString(e.out, b.String())
+	e.cursorRow = 0
+}
+
+func commonPrefix(a, b string) string {
+	ar, br := []rune(a), []rune(b)
+	n := 0
+	for n < len(ar) && n < len(br) && ar[n] == br[n] {
+		n++
+	}
+
+	return string(ar[:n])
+}
+
+// handleSearch applies a key during a reverse search and reports whether it
+// was used up. Keys that end the search leave the match in the line and
+// return false so they also take their normal effect.
+func (e *Editor) handleSearch(ev Event) bool {
+	switch ev.Key {
+	case KeyRune:
+		if !unicode.IsPrint(ev.Rune) {
+			return true
+		}
+		e.search.query = append(e.search.query, ev.Rune)
+		from := e.search.match
+		if from < 0 {
+			from = e.history.Len() - 1
+		}
+		e.findMatch(from)
+		return true
+	case KeyBackspace:
+		if len(e.search.query) > 0 {
+			e.search.query = e.search.query[:len(e.search.query)-1]
+		}
+		e.findMatch(e.history.Len() - 1)
+		return true
+	case KeyCtrlR:
+		if e.search.match > 0 {
+			e.findMatch(e.search.match - 1)
+		}
+		return true
+	case KeyEsc, KeyCtrlG:
+		e.buf = e.search.original
+		e.pos = len(e.buf)
+		e.search = nil
+		return true
+	}
+
+	e.search = nil
+	return false
+}
+
+// findMatch searches the history backwards from index from for an entry
+// containing the query and loads it into the line. The match is kept when
+// nothing older matches.
+func (e *Editor) findMatch(from int) {
+	query := string(e.search.query)
+	entries := e.history.Entries()
+
+	for i := from; i >= 0; i-- {
+		if at := strings.Index(entries[i], query); at >= 0 {
+			e.search.match = i
+			e.buf = []rune(entries[i])
+			e.pos = len([]rune(entries[i][:at]))
+			e.historyIndex = i
+			return
+		}
+	}
+
+	if e.search.match < 0 || !strings.Contains(entries[e.search.match], query) {
+		e.search.match = -1
+	}
+}
+
+// recall replaces the line with history entry i. Index len(history) is the
+// line that was being typed before browsing history.
+func (e *Editor) recall(i int) {
+	if i < 0 || i > e.history.Len() || i == e.historyIndex {
+		return
+	}
+
+	if e.historyIndex == e.history.Len() {
+		e.draft = append([]rune(nil), e.buf...)
+	}
+
+	e.historyIndex = i
+	if i == e.history.Len() {
+		e.buf = e.draft
+	} else {
+		e.buf = []rune(e.history.Entries()[i])
+	}
+	e.pos = len(e.buf)
+}
+
+// refresh redraws the prompt and line from the row the prompt starts on and
+// puts the cursor back where it belongs.
+func (e *Editor) refresh() {
+	var b strings.Builder
+
+	if e.cursorRow > 0 {
+		fmt.Fprintf(&b, "\x1b[%dA", e.cursorRow)
+	}
+	prompt := e.displayPrompt()
+
+	b.WriteString("\r\x1b[J")
+	b.WriteString(prompt)
+	b.WriteString(string(e.buf))
+
+	text := append([]rune(prompt), e.buf...)
+	positions := layout(text, e.width)
+	end := positions[len(text)]
+
+	// A line that exactly fills its last row leaves the terminal waiting to
+	// wrap; move to the next row so the cursor math below holds.
+	if end.col == 0 && end.row > 0 {
+		b.WriteString("\r\n")
+	}
+
+	cursor := positions[len([]rune(prompt))+e.pos]
+	if up := end.row - cursor.row; up > 0 {
+		fmt.Fprintf(&b, "\x1b[%dA", up)
+	}
+	b.WriteString("\r")
+	if cursor.col > 0 {
+		fmt.Fprintf(&b, "\x1b[%dC", cursor.col)
+	}
+
+	e.cursorRow = cursor.row
+	io.WriteString(e.out, b.String())
+}
+
+// displayPrompt returns the prompt, or the search status during a reverse
+// search.
+func (e *Editor) displayPrompt() string {
+	if e.search == nil {
+		return e.prompt
+	}
+
+	status := "reverse-i-search"
+	if e.search.match < 0 && len(e.search.query) > 0 {
+		status = "failing reverse-i-search"
+	}
+	return fmt.Sprintf("(%s)`%s': ", status, string(e.search.query))
+}
+
+type position struct {
+	row int
+	col int
+}
+
+// layout returns the screen position at which each rune of text starts, plus
+// the position just past the last rune, on a terminal width columns wide.
+// Wide characters that don't fit at the end of a row move to the next one,
+// as terminals do. ANSI escape sequences such as colors take no space.
+func layout(text []rune, width int) []position {
+	positions := make([]position, len(text)+1)
+
+	row, col := 0, 0
+	escape := false
+	for i, r := range text {
+		if r == '\x1b' || escape {
+			// A CSI sequence such as "\x1b[1;31m" runs until its final
+			// byte, the first one that is not a parameter or intermediate.
+			escape = r == '\x1b' || r == '[' || (r >= ' ' && r <= '?')
+			positions[i] = position{row: row, col: col}
+			continue
+		}
+
+		w := runewidth.RuneWidth(r)
+		if col+w > width {
+			row++
+			col = 0
+		}
+		positions[i] = position{row: row, col: col}
+
+		col += w
+		if col >= width {
+			row++
+			col = 0
+		}
+	}
+	positions[len(text)] = position{row: row, col: col}
+
+	return positions
+}
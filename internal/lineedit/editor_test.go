@@ -0,0 +1,423 @@
+package lineedit
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func typeString(e *Editor, s string) {
+	for _, r := range s {
+		e.Handle(Event{Key: KeyRune, Rune: r})
+	}
+}
+
+func press(e *Editor, keys ...Key) {
+	for _, k := range keys {
+		e.Handle(Event{Key: k})
+	}
+}
+
+func TestEditing(t *testing.T) {
+	cases := []struct {
+		name   string
+		edit   func(e *Editor)
+		line   string
+		cursor int
+	}{
+		{
+			name:   "typing appends",
+			edit:   func(e *Editor) { typeString(e, "catch pikachu") },
+			line:   "catch pikachu",
+			cursor: 13,
+		},
+		{
+			name: "insert mid-line",
+			edit: func(e *Editor) {
+				typeString(e, "cach")
+				press(e, KeyLeft, KeyLeft)
+				typeString(e, "t")
+			},
+			line:   "catch",
+			cursor: 3,
+		},
+		{
+			name: "backspace mid-line",
+			edit: func(e *Editor) {
+				typeString(e, "catxch")
+				press(e, KeyLeft, KeyLeft, KeyBackspace)
+			},
+			line:   "catch",
+			cursor: 3,
+		},
+		{
+			name: "delete forward",
+			edit: func(e *Editor) {
+				typeString(e, "xmap")
+				press(e, KeyHome, KeyDelete)
+			},
+			line:   "map",
+			cursor: 0,
+		},
+		{
+			name: "ctrl-d deletes forward",
+			edit: func(e *Editor) {
+				typeString(e, "xmap")
+				press(e, KeyHome, KeyCtrlD)
+			},
+			line:   "map",
+			cursor: 0,
+		},
+		{
+			name: "delete at end does nothing",
+			edit: func(e *Editor) {
+				typeString(e, "map")
+				press(e, KeyDelete, KeyRight)
+			},
+			line:   "map",
+			cursor: 3,
+		},
+		{
+			name: "ctrl-a and ctrl-e",
+			edit: func(e *Editor) {
+				typeString(e, "plore")
+				press(e, KeyCtrlA)
+				typeString(e, "ex")
+				press(e, KeyCtrlE)
+				typeString(e, " x")
+			},
+			line:   "explore x",
+			cursor: 9,
+		},
+		{
+			name: "ctrl-k kills to end",
+			edit: func(e *Editor) {
+				typeString(e, "inspect pikachu")
+				press(e, KeyHome, KeyRight, KeyRight, KeyRight, KeyRight, KeyRight, KeyRight, KeyRight, KeyCtrlK)
+			},
+			line:   "inspect",
+			cursor: 7,
+		},
+		{
+			name: "ctrl-u kills to start",
+			edit: func(e *Editor) {
+				typeString(e, "catch pikachu")
+				press(e, KeyLeft, KeyLeft, KeyLeft, KeyLeft, KeyLeft, KeyLeft, KeyLeft, KeyCtrlU)
+			},
+			line:   "pikachu",
+			cursor: 0,
+		},
+		{
+			name: "ctrl-w deletes the previous word",
+			edit: func(e *Editor) {
+				typeString(e, "catch  pikachu  ")
+				press(e, KeyCtrlW)
+			},
+			line:   "catch  ",
+			cursor: 7,
+		},
+		{
+			name:   "control characters are ignored",
+			edit:   func(e *Editor) { typeString(e, "m\x00ap") },
+			line:   "map",
+			cursor: 3,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			e := New(io.Discard, "> ")
+			e.Start()
+			c.edit(e)
+
+			if e.Line() != c.line {
+				t.Errorf("expected line %q, got %q", c.line, e.Line())
+			}
+			if e.Cursor() != c.cursor {
+				t.Errorf("expected cursor %d, got %d", c.cursor, e.Cursor())
+			}
+		})
+	}
+}
+
+func TestEnterReturnsLine(t *testing.T) {
+	e := New(io.Discard, "> ")
+	e.Start()
+	typeString(e, "map")
+
+	if _, done := e.Handle(Event{Key: KeyLeft}); done {
+		t.Fatalf("expected only Enter to finish the line")
+	}
+
+	line, done := e.Handle(Event{Key: KeyEnter})
+	if !done || line != "map" {
+		t.Errorf("expected map, got %q (done %v)", line, done)
+	}
+}
+
+func TestCtrlCClearsLine(t *testing.T) {
+	var out strings.Builder
+	e := New(&out, "> ")
+	e.Start()
+	typeString(e, "catch pika")
+	press(e, KeyHome)
+
+	out.Reset()
+	if _, done := e.Handle(Event{Key: KeyCtrlC}); done {
+		t.Fatalf("expected Ctrl-C not to finish the line")
+	}
+	if e.Line() != "" || e.Cursor() != 0 {
+		t.Errorf("expected an empty line, got %q at %d", e.Line(), e.Cursor())
+	}
+	if !strings.Contains(out.String(), "catch pika\r\x1b[12C^C\r\n") || !strings.HasSuffix(out.String(), "\r\x1b[J> \r\x1b[2C") {
+		t.Errorf("expected ^C and a fresh prompt, got %q", out.String())
+	}
+}
+
+func TestResize(t *testing.T) {
+	var out strings.Builder
+	e := New(&out, "> ")
+	e.SetWidth(80)
+	e.Start()
+	typeString(e, "explore canalave")
+
+	// The terminal shrinks to 10 columns and rewraps the 18 column line onto
+	// two rows, with the cursor on the second.
+	out.Reset()
+	e.SetWidth(10)
+	e.Handle(Event{Key: KeyResize})
+	if !strings.HasPrefix(out.String(), "\x1b[1A\r\x1b[J> explore canalave") {
+		t.Errorf("expected redraw to start from the prompt's row, got %q", out.String())
+	}
+}
+
+func TestHistory(t *testing.T) {
+	e := New(io.Discard, "> ")
+	e.AddHistory("map")
+	e.AddHistory("explore canalave-city-area")
+	e.Start()
+	typeString(e, "cat")
+
+	steps := []struct {
+		key  Key
+		line string
+	}{
+		{key: KeyUp, line: "explore canalave-city-area"},
+		{key: KeyUp, line: "map"},
+		{key: KeyUp, line: "map"},
+		{key: KeyDown, line: "explore canalave-city-area"},
+		{key: KeyDown, line: "cat"},
+		{key: KeyDown, line: "cat"},
+	}
+
+	for i, step := range steps {
+		press(e, step.key)
+		if e.Line() != step.line {
+			t.Errorf("step %d: expected %q, got %q", i, step.line, e.Line())
+		}
+	}
+}
+
+func TestLayout(t *testing.T) {
+	cases := []struct {
+		name  string
+		text  string
+		width int
+		end   position
+	}{
+		{name: "fits on one row", text: "abc", width: 10, end: position{row: 0, col: 3}},
+		{name: "exactly fills a row", text: "abcd", width: 4, end: position{row: 1, col: 0}},
+		{name: "wraps", text: "abcdef", width: 4, end: position{row: 1, col: 2}},
+		{name: "wide characters count double", text: "ピカチュウ", width: 20, end: position{row: 0, col: 10}},
+		{name: "wide character moves to the next row", text: "abcピ", width: 4, end: position{row: 1, col: 2}},
+		{name: "colors take no space", text: "\x1b[1;31mab\x1b[0mc", width: 4, end: position{row: 0, col: 3}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			positions := layout([]rune(c.text), c.width)
+			if end := positions[len(positions)-1]; end != c.end {
+				t.Errorf("expected end at %+v, got %+v", c.end, end)
+			}
+		})
+	}
+}
+
+func TestRefreshLongLine(t *testing.T) {
+	var out strings.Builder
+	e := New(&out, "> ")
+	e.SetWidth(10)
+	e.Start()
+	typeString(e, "explore canalave")
+
+	// 18 columns on a 10 column terminal: the cursor is on the second row.
+	out.Reset()
+	press(e, KeyHome)
+	if !strings.HasPrefix(out.String(), "\x1b[1A\r\x1b[J> explore canalave") {
+		t.Errorf("expected redraw to start from the prompt's row, got %q", out.String())
+	}
+	if !strings.HasSuffix(out.String(), "\x1b[1A\r\x1b[2C") {
+		t.Errorf("expected cursor to move back to just after the prompt, got %q", out.String())
+	}
+}
+
+func wordCompleter(words ...string) Completer {
+	return func(line []rune, pos int) ([]string, int) {
+		start := pos
+		for start > 0 && line[start-1] != ' ' {
+			start--
+		}
+
+		var candidates []string
+		for _, w := range words {
+			if strings.HasPrefix(w, string(line[start:pos])) {
+				candidates = append(candidates, w)
+			}
+		}
+
+		return candidates, start
+	}
+}
+
+func TestTabCompletion(t *testing.T) {
+	cases := []struct {
+		name   string
+		typed  string
+		line   string
+		cursor int
+	}{
+		{name: "single candidate", typed: "ex", line: "explore ", cursor: 8},
+		{name: "common prefix", typed: "ma", line: "map", cursor: 3},
+		{name: "no candidates", typed: "zz", line: "zz", cursor: 2},
+		{name: "completes the last word", typed: "catch pi", line: "catch pikachu ", cursor: 14},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			e := New(io.Discard, "> ")
+			e.SetCompleter(wordCompleter("explore", "map", "mapb", "pikachu"))
+			e.Start()
+			typeString(e, c.typed)
+			press(e, KeyTab)
+
+			if e.Line() != c.line || e.Cursor() != c.cursor {
+				t.Errorf("expected %q with cursor %d, got %q with cursor %d", c.line, c.cursor, e.Line(), e.Cursor())
+			}
+		})
+	}
+}
+
+func TestTabCompletionMidLine(t *testing.T) {
+	e := New(io.Discard, "> ")
+	e.SetCompleter(wordCompleter("explore"))
+	e.Start()
+	typeString(e, "ex pastoria-city-area")
+	press(e, KeyHome, KeyRight, KeyRight, KeyTab)
+
+	if e.Line() != "explore  pastoria-city-area" || e.Cursor() != 8 {
+		t.Errorf("unexpected line %q with cursor %d", e.Line(), e.Cursor())
+	}
+}
+
+func TestDoubleTabListsCandidates(t *testing.T) {
+	var out strings.Builder
+	e := New(&out, "> ")
+	e.SetCompleter(wordCompleter("map", "mapb"))
+	e.Start()
+	typeString(e, "map")
+
+	out.Reset()
+	press(e, KeyTab)
+	if strings.Contains(out.String(), "mapb") {
+		t.Errorf("expected a single Tab not to list candidates, got %q", out.String())
+	}
+
+	out.Reset()
+	press(e, KeyTab)
+	if !strings.Contains(out.String(), "\r\nmap   mapb\r\n") {
+		t.Errorf("expected candidates to be listed, got %q", out.String())
+	}
+	if !strings.HasSuffix(out.String(), "> map\r\x1b[5C") {
+		t.Errorf("expected the line to be redrawn below the list, got %q", out.String())
+	}
+	if e.Line() != "map" {
+		t.Errorf("expected line to be unchanged, got %q", e.Line())
+	}
+}
+
+func TestReverseSearch(t *testing.T) {
+	newEditor := func() *Editor {
+		e := New(io.Discard, "> ")
+		for _, line := range []string{"explore canalave-city-area", "catch tentacool", "explore pastoria-city-area", "map"} {
+			e.AddHistory(line)
+		}
+		e.Start()
+		return e
+	}
+
+	t.Run("finds the most recent match", func(t *testing.T) {
+		e := newEditor()
+		press(e, KeyCtrlR)
+		typeString(e, "expl")
+		if e.Line() != "explore pastoria-city-area" || e.Cursor() != 0 {
+			t.Errorf("unexpected match %q at %d", e.Line(), e.Cursor())
+		}
+	})
+
+	t.Run("ctrl-r moves to older matches", func(t *testing.T) {
+		e := newEditor()
+		press(e, KeyCtrlR)
+		typeString(e, "city")
+		press(e, KeyCtrlR)
+		if e.Line() != "explore canalave-city-area" {
+			t.Errorf("expected the older match, got %q", e.Line())
+		}
+		press(e, KeyCtrlR)
+		if e.Line() != "explore canalave-city-area" {
+			t.Errorf("expected to stay on the oldest match, got %q", e.Line())
+		}
+	})
+
+	t.Run("enter runs the match", func(t *testing.T) {
+		e := newEditor()
+		press(e, KeyCtrlR)
+		typeString(e, "tenta")
+		line, done := e.Handle(Event{Key: KeyEnter})
+		if !done || line != "catch tentacool" {
+			t.Errorf("expected the match to be submitted, got %q (done %v)", line, done)
+		}
+	})
+
+	t.Run("other keys accept the match for editing", func(t *testing.T) {
+		e := newEditor()
+		press(e, KeyCtrlR)
+		typeString(e, "tenta")
+		press(e, KeyCtrlE)
+		typeString(e, "!")
+		if e.Line() != "catch tentacool!" {
+			t.Errorf("expected to keep editing the match, got %q", e.Line())
+		}
+	})
+
+	t.Run("escape restores the line", func(t *testing.T) {
+		e := newEditor()
+		typeString(e, "in")
+		press(e, KeyCtrlR)
+		typeString(e, "map")
+		press(e, KeyEsc)
+		if e.Line() != "in" {
+			t.Errorf("expected the original line back, got %q", e.Line())
+		}
+	})
+
+	t.Run("failing search is shown", func(t *testing.T) {
+		var out strings.Builder
+		e := newEditor()
+		e.out = &out
+		press(e, KeyCtrlR)
+		typeString(e, "zz")
+		if !strings.Contains(out.String(), "(failing reverse-i-search)`zz': ") {
+			t.Errorf("expected a failing search prompt, got %q", out.String())
+		}
+	})
+}
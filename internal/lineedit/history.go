@@ -0,0 +1,133 @@
+package lineedit
+
+import (
+	"bufio"
+	"errors"
+	"io/fs"
+	"os"
+	"pokedexcli/internal/atomicfile"
+	"slices"
+	"strings"
+)
+
+// History is the list of previously entered lines, oldest first. Each
+// entry keeps the number it was given when it was added, so numbers do not
+// shift when older entries are dropped or removed as duplicates.
+type History struct {
+	entries []string
+	numbers []int
+	added   int
+	limit   int
+	dedupe  bool
+}
+
+// NewHistory returns a history that keeps at most limit entries (no limit
+// when limit is 0). With dedupe set, adding a line removes any earlier copy
+// of it.
+func NewHistory(limit int, dedupe bool) *History {
+	return &History{
+		limit:  limit,
+		dedupe: dedupe,
+	}
+}
+
+// Configure changes the limit and dedupe setting the history was created
+// with. Entries beyond the new limit are dropped, oldest first; earlier
+// duplicates are left until their line is added again.
+func (h *History) Configure(limit int, dedupe bool) {
+	h.limit = limit
+	h.dedupe = dedupe
+	h.trim()
+}
+
+func (h *History) Add(line string) {
+	if line == "" {
+		return
+	}
+
+	if h.dedupe {
+		for i, entry := range h.entries {
+			if entry == line {
+				h.entries = append(h.entries[:i], h.entries[i+1:]...)
+				h.numbers = append(h.numbers[:i], h.numbers[i+1:]...)
+				break
+			}
+		}
+	}
+
+	h.added++
+	h.entries = append(h.entries, line)
+	h.numbers = append(h.numbers, h.added)
+	h.trim()
+}
+
+// Entries returns the history, oldest first.
+func (h *History) Entries() []string {
+	return h.entries
+}
+
+// Numbers returns the number of each entry, in the same order as Entries.
+// The first line added is number 1.
+func (h *History) Numbers() []int {
+	return h.numbers
+}
+
+// Lookup returns the entry with number n.
+func (h *History) Lookup(n int) (string, bool) {
+	i, found := slices.BinarySearch(h.numbers, n)
+	if !found {
+		return "", false
+	}
+	return h.entries[i], true
+}
+
+func (h *History) Len() int {
+	return len(h.entries)
+}
+
+// Clear removes every entry. Numbering starts again from 1.
+func (h *History) Clear() {
+	h.entries = nil
+	h.numbers = nil
+	h.added = 0
+}
+
+// Load reads entries from a file with one line per entry, replacing the
+// current history. A missing file leaves the history empty.
+func (h *History) Load(path string) error {
+	h.Clear()
+
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		h.Add(scanner.Text())
+	}
+
+	return scanner.Err()
+}
+
+// Save writes the history to path, one line per entry.
+func (h *History) Save(path string) error {
+	var data strings.Builder
+	for _, entry := range h.entries {
+		data.WriteString(entry)
+		data.WriteString("\n")
+	}
+
+	return atomicfile.Write(path, []byte(data.String()))
+}
+
+func (h *History) trim() {
+	if h.limit > 0 && len(h.entries) > h.limit {
+		h.entries = h.entries[len(h.entries)-h.limit:]
+		h.numbers = h.numbers[len(h.numbers)-h.limit:]
+	}
+}
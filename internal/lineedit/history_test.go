@@ -0,0 +1,108 @@
+package lineedit
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestHistoryAdd(t *testing.T) {
+	cases := []struct {
+		name     string
+		limit    int
+		dedupe   bool
+		lines    []string
+		expected []string
+		numbers  []int
+	}{
+		{
+			name:     "keeps everything",
+			lines:    []string{"map", "map", "mapb"},
+			expected: []string{"map", "map", "mapb"},
+			numbers:  []int{1, 2, 3},
+		},
+		{
+			name:     "dedupe keeps the latest copy",
+			dedupe:   true,
+			lines:    []string{"map", "mapb", "map"},
+			expected: []string{"mapb", "map"},
+			numbers:  []int{2, 3},
+		},
+		{
+			name:     "limit drops the oldest",
+			limit:    2,
+			lines:    []string{"help", "map", "mapb"},
+			expected: []string{"map", "mapb"},
+			numbers:  []int{2, 3},
+		},
+		{
+			name:     "empty lines are skipped",
+			lines:    []string{"", "map"},
+			expected: []string{"map"},
+			numbers:  []int{1},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			h := NewHistory(c.limit, c.dedupe)
+			for _, line := range c.lines {
+				h.Add(line)
+			}
+			if !reflect.DeepEqual(h.Entries(), c.expected) {
+				t.Errorf("expected %v, got %v", c.expected, h.Entries())
+			}
+			if !reflect.DeepEqual(h.Numbers(), c.numbers) {
+				t.Errorf("expected numbers %v, got %v", c.numbers, h.Numbers())
+			}
+			for i, n := range c.numbers {
+				if entry, ok := h.Lookup(n); !ok || entry != c.expected[i] {
+					t.Errorf("expected entry %d to be %q, got %q", n, c.expected[i], entry)
+				}
+			}
+		})
+	}
+}
+
+func TestHistoryConfigure(t *testing.T) {
+	h := NewHistory(0, false)
+	for _, line := range []string{"help", "map", "mapb"} {
+		h.Add(line)
+	}
+
+	h.Configure(2, true)
+	if expected := []string{"map", "mapb"}; !reflect.DeepEqual(h.Entries(), expected) {
+		t.Errorf("expected %v, got %v", expected, h.Entries())
+	}
+
+	h.Add("map")
+	if expected := []string{"mapb", "map"}; !reflect.DeepEqual(h.Entries(), expected) {
+		t.Errorf("expected %v, got %v", expected, h.Entries())
+	}
+}
+
+func TestHistorySaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "history")
+
+	missing := NewHistory(0, false)
+	if err := missing.Load(path); err != nil {
+		t.Fatalf("expected a missing file to load as empty history, got %v", err)
+	}
+
+	h := NewHistory(0, false)
+	for _, line := range []string{"map", "explore pastoria-city-area", "catch tentacool"} {
+		h.Add(line)
+	}
+	if err := h.Save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded := NewHistory(2, false)
+	if err := loaded.Load(path); err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"explore pastoria-city-area", "catch tentacool"}
+	if !reflect.DeepEqual(loaded.Entries(), expected) {
+		t.Errorf("expected %v, got %v", expected, loaded.Entries())
+	}
+}
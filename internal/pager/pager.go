@@ -0,0 +1,299 @@
+// Package pager shows text one screen at a time on a raw-mode terminal, with
+// scrolling and search, like a small less.
+package pager
+
+import (
+	"fmt"
+	"io"
+	"pokedexcli/internal/lineedit"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mattn/go-runewidth"
+)
+
+const hint = "space: next page  b: back  /: search  n: next match  q: quit"
+
+type Pager struct {
+	out    io.Writer
+	text   string
+	width  int
+	height int
+
+	// rows is the text wrapped to the terminal width, and plain the same
+	// rows without escape sequences, for searching.
+	rows  []string
+	plain []string
+	top   int
+
+	// query is set while a search is being typed; pattern is the last
+	// search made.
+	query   *[]rune
+	pattern string
+	message string
+}
+
+// Fits reports whether text fits on a terminal width by height without
+// scrolling, leaving the last row for the prompt.
+func Fits(text string, width, height int) bool {
+	return len(wrap(text, width)) < height
+}
+
+// New returns a pager for text on a terminal width by height.
+func New(out io.Writer, text string, width, height int) *Pager {
+	p := &Pager{out: out, text: text}
+	p.setSize(width, height)
+
+	return p
+}
+
+// Resize rewraps the text for a terminal width by height and redraws.
+func (p *Pager) Resize(width, height int) {
+	p.setSize(width, height)
+	p.top = min(p.top, p.lastTop())
+	p.draw()
+}
+
+func (p *Pager) setSize(width, height int) {
+	p.width = max(width, 1)
+	p.height = max(height, 2)
+
+	p.rows = wrap(p.text, p.width)
+	p.plain = make([]string, len(p.rows))
+	for i, row := range p.rows {
+		p.plain[i] = stripEscapes(row)
+	}
+}
+
+// Start switches to the terminal's alternate screen and shows the first
+// page.
+func (p *Pager) Start() {
+	io.WriteString(p.out, "\x1b[?1049h")
+	p.draw()
+}
+
+// Handle applies a key and redraws. It returns true when the user has quit,
+// after restoring the screen the pager started on.
+func (p *Pager) Handle(ev lineedit.Event) bool {
+	p.message = ""
+
+	if p.query != nil {
+		p.handleQuery(ev)
+		p.draw()
+		return false
+	}
+
+	page := p.pageSize()
+	switch {
+	case ev.Key == lineedit.KeyRune && (ev.Rune == 'q' || ev.Rune == 'Q'), ev.Key == lineedit.KeyEsc, ev.Key == lineedit.KeyCtrlC:
+		io.WriteString(p.out, "\x1b[?1049l")
+		return true
+	case ev.Key == lineedit.KeyRune && (ev.Rune == ' ' || ev.Rune == 'f'), ev.Key == lineedit.KeyPageDown:
+		p.scroll(page)
+	case ev.Key == lineedit.KeyRune && ev.Rune == 'b', ev.Key == lineedit.KeyPageUp:
+		p.scroll(-page)
+	case ev.Key == lineedit.KeyRune && ev.Rune == 'j', ev.Key == lineedit.KeyDown, ev.Key == lineedit.KeyEnter:
+		p.scroll(1)
+	case ev.Key == lineedit.KeyRune && ev.Rune == 'k', ev.Key == lineedit.KeyUp:
+		p.scroll(-1)
+	case ev.Key == lineedit.KeyRune && ev.Rune == 'g', ev.Key == lineedit.KeyHome:
+		p.top = 0
+	case ev.Key == lineedit.KeyRune && ev.Rune == 'G', ev.Key == lineedit.KeyEnd:
+		p.top = p.lastTop()
+	case ev.Key == lineedit.KeyRune && ev.Rune == '/':
+		p.query = new([]rune)
+	case ev.Key == lineedit.KeyRune && ev.Rune == 'n':
+		p.find(p.top+1, 1)
+	case ev.Key == lineedit.KeyRune && ev.Rune == 'N':
+		p.find(p.top-1, -1)
+	}
+
+	p.draw()
+	return false
+}
+
+// Top returns the index of the first row on screen.
+func (p *Pager) Top() int {
+	return p.top
+}
+
+func (p *Pager) handleQuery(ev lineedit.Event) {
+	switch ev.Key {
+	case lineedit.KeyRune:
+		*p.query = append(*p.query, ev.Rune)
+	case lineedit.KeyBackspace:
+		if len(*p.query) == 0 {
+			p.query = nil
+			return
+		}
+		*p.query = (*p.query)[:len(*p.query)-1]
+	case lineedit.KeyEsc, lineedit.KeyCtrlC:
+		p.query = nil
+	case lineedit.KeyEnter:
+		if len(*p.query) > 0 {
+			p.pattern = string(*p.query)
+		}
+		p.query = nil
+		p.find(p.top+1, 1)
+	}
+}
+
+// find moves to the first row from start, in direction dir, that matches the
+// search pattern.
+func (p *Pager) find(start, dir int) {
+	if p.pattern == "" {
+		p.message = "No previous search"
+		return
+	}
+
+	for i := start; i >= 0 && i < len(p.plain); i += dir {
+		if start, _ := indexFold(p.plain[i], p.pattern); start >= 0 {
+			p.top = min(i, p.lastTop())
+			return
+		}
+	}
+
+	p.message = "Pattern not found"
+}
+
+func (p *Pager) pageSize() int {
+	return p.height - 1
+}
+
+func (p *Pager) lastTop() int {
+	return max(len(p.rows)-p.pageSize(), 0)
+}
+
+func (p *Pager) scroll(n int) {
+	p.top = min(max(p.top+n, 0), p.lastTop())
+}
+
+func (p *Pager) draw() {
+	var b strings.Builder
+
+	b.WriteString("\x1b[H\x1b[2J")
+	end := min(p.top+p.pageSize(), len(p.rows))
+	for i := p.top; i < end; i++ {
+		b.WriteString(p.highlight(i))
+		b.WriteString("\x1b[0m\r\n")
+	}
+	for i := end; i < p.top+p.pageSize(); i++ {
+		b.WriteString("~\r\n")
+	}
+
+	switch {
+	case p.query != nil:
+		b.WriteString("/" + string(*p.query))
+	case p.message != "":
+		b.WriteString("\x1b[7m" + truncate(p.message, p.width) + "\x1b[0m")
+	default:
+		status := fmt.Sprintf("lines %d-%d of %d", p.top+1, end, len(p.rows))
+		if end == len(p.rows) {
+			status += " (END)"
+		}
+		b.WriteString("\x1b[7m" + truncate(status+"  "+hint, p.width) + "\x1b[0m")
+	}
+
+	io.WriteString(p.out, b.String())
+}
+
+// highlight returns row i with matches of the search pattern in reverse
+// video. Matching rows lose their own colors.
+func (p *Pager) highlight(i int) string {
+	if p.pattern == "" {
+		return p.rows[i]
+	}
+
+	plain := p.plain[i]
+	if start, _ := indexFold(plain, p.pattern); start < 0 {
+		return p.rows[i]
+	}
+
+	var b strings.Builder
+	for {
+		start, end := indexFold(plain, p.pattern)
+		if start < 0 {
+			b.WriteString(plain)
+			return b.String()
+		}
+		b.WriteString(plain[:start] + "\x1b[7m" + plain[start:end] + "\x1b[27m")
+		plain = plain[end:]
+	}
+}
+
+// indexFold returns the byte offsets in s of the first match of pattern,
+// ignoring case, or -1, -1 if there is none. Runes are compared one at a
+// time with strings.EqualFold, so the offsets are those of s even where a
+// rune's other case takes a different number of bytes.
+func indexFold(s, pattern string) (start, end int) {
+	for start := range s {
+		end := start
+		matched := true
+		for _, want := range pattern {
+			r, size := utf8.DecodeRuneInString(s[end:])
+			if size == 0 || !strings.EqualFold(string(r), string(want)) {
+				matched = false
+				break
+			}
+			end += size
+		}
+		if matched {
+			return start, end
+		}
+	}
+
+	return -1, -1
+}
+
+// wrap splits text into rows no wider than width columns. Escape sequences
+// take no space.
+func wrap(text string, width int) []string {
+	text = strings.TrimSuffix(text, "\n")
+	if text == "" {
+		return nil
+	}
+
+	var rows []string
+	for _, line := range strings.Split(text, "\n") {
+		var row strings.Builder
+		col := 0
+		escape := false
+		for _, r := range line {
+			if r == '\x1b' || escape {
+				escape = r == '\x1b' || r == '[' || (r >= ' ' && r <= '?')
+				row.WriteRune(r)
+				continue
+			}
+
+			w := runewidth.RuneWidth(r)
+			if col+w > width {
+				rows = append(rows, row.String())
+				row.Reset()
+				col = 0
+			}
+			row.WriteRune(r)
+			col += w
+		}
+		rows = append(rows, row.String())
+	}
+
+	return rows
+}
+
+func stripEscapes(s string) string {
+	var b strings.Builder
+	escape := false
+	for _, r := range s {
+		if r == '\x1b' || escape {
+			escape = r == '\x1b' || r == '[' || (r >= ' ' && r <= '?')
+			continue
+		}
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
+
+func truncate(s string, width int) string {
+	return runewidth.Truncate(s, width, "")
+}
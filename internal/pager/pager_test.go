@@ -0,0 +1,198 @@
+package pager
+
+import (
+	"fmt"
+	"io"
+	"pokedexcli/internal/lineedit"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func numbered(n int) string {
+	var b strings.Builder
+	for i := 1; i <= n; i++ {
+		fmt.Fprintf(&b, "line %d\n", i)
+	}
+	return b.String()
+}
+
+func key(k lineedit.Key) lineedit.Event {
+	return lineedit.Event{Key: k}
+}
+
+func char(r rune) lineedit.Event {
+	return lineedit.Event{Key: lineedit.KeyRune, Rune: r}
+}
+
+func TestWrap(t *testing.T) {
+	cases := []struct {
+		text     string
+		width    int
+		expected []string
+	}{
+		{text: "", width: 10, expected: nil},
+		{text: "abc\n", width: 10, expected: []string{"abc"}},
+		{text: "abc\n\ndef", width: 10, expected: []string{"abc", "", "def"}},
+		{text: "abcdef\n", width: 4, expected: []string{"abcd", "ef"}},
+		{text: "\x1b[31mabcd\x1b[0mef", width: 4, expected: []string{"\x1b[31mabcd\x1b[0m", "ef"}},
+		{text: "abcピ", width: 4, expected: []string{"abc", "ピ"}},
+	}
+
+	for _, c := range cases {
+		if actual := wrap(c.text, c.width); !reflect.DeepEqual(actual, c.expected) {
+			t.Errorf("wrap(%q, %d): expected %q, got %q", c.text, c.width, c.expected, actual)
+		}
+	}
+}
+
+func TestFits(t *testing.T) {
+	if !Fits(numbered(9), 80, 10) {
+		t.Errorf("expected 9 lines to fit 10 rows")
+	}
+	if Fits(numbered(10), 80, 10) {
+		t.Errorf("expected 10 lines not to fit 10 rows, leaving room for the prompt")
+	}
+	if Fits(strings.Repeat("x", 50)+"\n", 10, 5) {
+		t.Errorf("expected a line wrapping to 5 rows not to fit 5 rows")
+	}
+}
+
+func TestNavigation(t *testing.T) {
+	cases := []struct {
+		name   string
+		events []lineedit.Event
+		top    int
+	}{
+		{name: "space pages down", events: []lineedit.Event{char(' ')}, top: 9},
+		{name: "page down key", events: []lineedit.Event{key(lineedit.KeyPageDown), key(lineedit.KeyPageDown)}, top: 18},
+		{name: "down arrow", events: []lineedit.Event{key(lineedit.KeyDown), key(lineedit.KeyDown)}, top: 2},
+		{name: "back", events: []lineedit.Event{char(' '), char(' '), char('b')}, top: 9},
+		{name: "up stops at the top", events: []lineedit.Event{key(lineedit.KeyUp)}, top: 0},
+		{name: "end", events: []lineedit.Event{char('G')}, top: 41},
+		{name: "paging stops at the end", events: []lineedit.Event{char('G'), char(' ')}, top: 41},
+		{name: "home", events: []lineedit.Event{char('G'), key(lineedit.KeyHome)}, top: 0},
+		{name: "search", events: []lineedit.Event{char('/'), char('2'), char('5'), key(lineedit.KeyEnter)}, top: 24},
+		{name: "next match", events: []lineedit.Event{char('/'), char('5'), key(lineedit.KeyEnter), char('n')}, top: 14},
+		{name: "previous match", events: []lineedit.Event{char('/'), char('5'), key(lineedit.KeyEnter), char('n'), char('N')}, top: 4},
+		{name: "search near the end", events: []lineedit.Event{char('/'), char('4'), char('8'), key(lineedit.KeyEnter)}, top: 41},
+		{name: "cancelled search", events: []lineedit.Event{char('/'), char('9'), key(lineedit.KeyEsc), char('n')}, top: 0},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var out strings.Builder
+			p := New(&out, numbered(50), 80, 10)
+			p.Start()
+
+			for _, ev := range c.events {
+				if p.Handle(ev) {
+					t.Fatalf("pager quit on %+v", ev)
+				}
+			}
+			if p.Top() != c.top {
+				t.Errorf("expected top row %d, got %d", c.top, p.Top())
+			}
+		})
+	}
+}
+
+func TestDraw(t *testing.T) {
+	var out strings.Builder
+	p := New(&out, numbered(50), 80, 4)
+	p.Start()
+
+	if !strings.HasPrefix(out.String(), "\x1b[?1049h\x1b[H\x1b[2Jline 1\x1b[0m\r\nline 2\x1b[0m\r\nline 3\x1b[0m\r\n\x1b[7mlines 1-3 of 50  space") {
+		t.Errorf("unexpected first page: %q", out.String())
+	}
+
+	out.Reset()
+	for _, ev := range []lineedit.Event{char('/'), char('n'), char('e'), char(' '), char('4')} {
+		p.Handle(ev)
+	}
+	if !strings.HasSuffix(out.String(), "\r\n/ne 4") {
+		t.Errorf("expected the search being typed on the last row, got %q", out.String())
+	}
+
+	out.Reset()
+	p.Handle(key(lineedit.KeyEnter))
+	if !strings.Contains(out.String(), "li\x1b[7mne 4\x1b[27m\x1b[0m\r\n") {
+		t.Errorf("expected the match to be highlighted, got %q", out.String())
+	}
+
+	out.Reset()
+	p.Handle(char('/'))
+	for _, r := range "mewtwo" {
+		p.Handle(char(r))
+	}
+	p.Handle(key(lineedit.KeyEnter))
+	if !strings.Contains(out.String(), "\x1b[7mPattern not found\x1b[0m") {
+		t.Errorf("expected a message for a failed search, got %q", out.String())
+	}
+
+	out.Reset()
+	if !p.Handle(char('q')) {
+		t.Fatalf("expected q to quit")
+	}
+	if out.String() != "\x1b[?1049l" {
+		t.Errorf("expected to leave the alternate screen, got %q", out.String())
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	cases := []struct {
+		text     string
+		pattern  string
+		expected string
+	}{
+		{text: "line 4", pattern: "ne 4", expected: "li\x1b[7mne 4\x1b[27m"},
+		{text: "Mime Jr. and mime", pattern: "MIME", expected: "\x1b[7mMime\x1b[27m Jr. and \x1b[7mmime\x1b[27m"},
+		{text: "\x1b[31mred\x1b[0m", pattern: "e", expected: "r\x1b[7me\x1b[27md"},
+		{text: "ȺȺȺȺȺȺabc", pattern: "abc", expected: "ȺȺȺȺȺȺ\x1b[7mabc\x1b[27m"},
+		{text: "ȺȺȺȺȺȺabc", pattern: "ⱥⱥ", expected: "\x1b[7mȺȺ\x1b[27m\x1b[7mȺȺ\x1b[27m\x1b[7mȺȺ\x1b[27mabc"},
+		{text: "abc", pattern: "abd", expected: "abc"},
+	}
+
+	for _, c := range cases {
+		p := New(io.Discard, c.text, 80, 4)
+		p.pattern = c.pattern
+		if got := p.highlight(0); got != c.expected {
+			t.Errorf("%q searched for %q: expected %q, got %q", c.text, c.pattern, c.expected, got)
+		}
+	}
+}
+
+func TestResize(t *testing.T) {
+	var out strings.Builder
+	p := New(&out, numbered(50), 80, 10)
+	p.Start()
+	p.Handle(char('G'))
+
+	// Taller: the last page now starts higher up.
+	out.Reset()
+	p.Resize(80, 20)
+	if p.Top() != 31 {
+		t.Errorf("expected top row 31 after growing, got %d", p.Top())
+	}
+	if !strings.Contains(out.String(), "lines 32-50 of 50 (END)") {
+		t.Errorf("expected a redraw at the new size, got %q", out.String())
+	}
+
+	// Narrower: every line wraps onto two rows of 4 columns.
+	p.Resize(4, 20)
+	if len(p.rows) != 100 {
+		t.Errorf("expected the text to be rewrapped to 100 rows, got %d", len(p.rows))
+	}
+}
+
+func TestCtrlCQuits(t *testing.T) {
+	p := New(io.Discard, numbered(50), 80, 10)
+	p.Start()
+
+	if p.Handle(char('/')) || p.Handle(key(lineedit.KeyCtrlC)) {
+		t.Fatalf("expected Ctrl-C to cancel the search without quitting")
+	}
+	if !p.Handle(key(lineedit.KeyCtrlC)) {
+		t.Errorf("expected Ctrl-C to quit")
+	}
+}
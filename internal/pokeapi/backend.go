@@ -0,0 +1,88 @@
+package pokeapi
+
+import (
+	"context"
+	"strings"
+)
+
+// Backend is what commands use to look things up. Client talks to the REST
+// API; GraphQLClient answers composite lookups with a single GraphQL query
+// and falls back to REST for everything else.
+type Backend interface {
+	GetLocations(pageURL string) (Locations, error)
+	GetLocationDetails(name string) (LocationDetails, error)
+	GetPokemonSummary(name string) (PokemonSummary, error)
+	GetPokemonProfile(ctx context.Context, name string) (PokemonProfile, error)
+	ResourceNames(resource string) ([]string, error)
+	Sync(resources []string, workers int, progress func(SyncProgress)) error
+}
+
+var _ Backend = (*Client)(nil)
+var _ Backend = (*GraphQLClient)(nil)
+
+// GetPokemonProfile looks up a Pokemon, its species and its evolution chain,
+// which takes three REST requests, all within ctx's deadline.
+func (c *Client) GetPokemonProfile(ctx context.Context, name string) (PokemonProfile, error) {
+	details, err := c.getPokemonSummary(ctx, name)
+	if err != nil {
+		return PokemonProfile{}, err
+	}
+
+	species := PokemonSpecies{}
+	if err := c.getJSON(ctx, c.resourceURL("pokemon-species", details.Species.Name), &species); err != nil {
+		return PokemonProfile{}, err
+	}
+
+	chain := EvolutionChain{}
+	if err := c.getJSON(ctx, species.EvolutionChain.URL, &chain); err != nil {
+		return PokemonProfile{}, err
+	}
+
+	profile := PokemonProfile{
+		Name:       details.Name,
+		Species:    species.Name,
+		Generation: species.Generation.Name,
+		Evolutions: chain.Chain.speciesNames(),
+	}
+
+	for _, genus := range species.Genera {
+		if genus.Language.Name == "en" {
+			profile.Genus = genus.Genus
+			break
+		}
+	}
+	for _, entry := range species.FlavorTextEntries {
+		if entry.Language.Name == "en" {
+			profile.FlavorText = cleanFlavorText(entry.FlavorText)
+			break
+		}
+	}
+	for _, t := range details.Types {
+		profile.Types = append(profile.Types, t.Type.Name)
+	}
+
+	return profile, nil
+}
+
+// speciesNames lists every species in the chain, stage by stage.
+func (link EvolutionLink) speciesNames() []string {
+	var names []string
+
+	stage := []EvolutionLink{link}
+	for len(stage) > 0 {
+		var next []EvolutionLink
+		for _, l := range stage {
+			names = append(names, l.Species.Name)
+			next = append(next, l.EvolvesTo...)
+		}
+		stage = next
+	}
+
+	return names
+}
+
+// cleanFlavorText joins the hard line and page breaks the games use in
+// flavor text into plain spaces.
+func cleanFlavorText(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
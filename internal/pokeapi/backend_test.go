@@ -0,0 +1,75 @@
+package pokeapi
+
+import (
+	"context"
+	"pokedexcli/internal/pokeapitest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func newTestBackends(t *testing.T) (*pokeapitest.Server, map[string]Backend) {
+	t.Helper()
+
+	server := pokeapitest.NewServer()
+	t.Cleanup(server.Close)
+
+	rest := NewClient(time.Minute, nil)
+	rest.SetBaseURL(server.BaseURL())
+
+	graphqlRest := NewClient(time.Minute, nil)
+	graphqlRest.SetBaseURL(server.BaseURL())
+
+	return server, map[string]Backend{
+		"rest":    rest,
+		"graphql": NewGraphQLClient(graphqlRest, server.GraphQLURL()),
+	}
+}
+
+func TestGetPokemonProfile(t *testing.T) {
+	_, backends := newTestBackends(t)
+
+	expected := PokemonProfile{
+		Name:       "pikachu",
+		Species:    "pikachu",
+		Genus:      "Mouse Pokémon",
+		Generation: "generation-i",
+		FlavorText: "When several of these POKéMON gather, their electricity could build and cause lightning storms.",
+		Types:      []string{"electric"},
+		Evolutions: []string{"pichu", "pikachu", "raichu"},
+	}
+
+	for name, backend := range backends {
+		t.Run(name, func(t *testing.T) {
+			profile, err := backend.GetPokemonProfile(context.Background(), "pikachu")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(profile, expected) {
+				t.Errorf("expected %+v, got %+v", expected, profile)
+			}
+
+			if _, err := backend.GetPokemonProfile(context.Background(), "missingno"); err != ErrNotFound {
+				t.Errorf("expected ErrNotFound, got %v", err)
+			}
+		})
+	}
+}
+
+func TestGraphQLProfileIsOneRequest(t *testing.T) {
+	server, backends := newTestBackends(t)
+
+	if _, err := backends["graphql"].GetPokemonProfile(context.Background(), "charmander"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := backends["graphql"].GetPokemonProfile(context.Background(), "charmander"); err != nil {
+		t.Fatal(err)
+	}
+
+	if n := server.Requests(pokeapitest.GraphQLPath); n != 1 {
+		t.Errorf("expected a single cached GraphQL request, got %d", n)
+	}
+	if n := server.Requests("/pokemon/charmander"); n != 0 {
+		t.Errorf("expected no REST requests, got %d", n)
+	}
+}
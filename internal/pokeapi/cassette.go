@@ -0,0 +1,166 @@
+package pokeapi
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// cassette is one recorded HTTP exchange, stored as a JSON file.
+type cassette struct {
+	Method     string          `json:"method"`
+	URL        string          `json:"url"`
+	StatusCode int             `json:"status_code"`
+	Header     http.Header     `json:"header"`
+	Body       json.RawMessage `json:"body,omitempty"`
+	RawBody    []byte          `json:"raw_body,omitempty"`
+}
+
+type recordingTransport struct {
+	dir  string
+	next http.RoundTripper
+}
+
+type replayTransport struct {
+	dir string
+}
+
+// NewRecordingTransport returns a transport that sends requests with next
+// (http.DefaultTransport when nil) and saves every response to dir.
+func NewRecordingTransport(dir string, next http.RoundTripper) (http.RoundTripper, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	return &recordingTransport{dir: dir, next: next}, nil
+}
+
+// NewReplayTransport returns a transport that answers requests from responses
+// previously saved to dir by a recording transport, without touching the
+// network. Requests with no recording fail.
+func NewReplayTransport(dir string) (http.RoundTripper, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", dir)
+	}
+
+	return &replayTransport{dir: dir}, nil
+}
+
+func (t *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	res, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	res.Body = io.NopCloser(bytes.NewReader(body))
+
+	entry := cassette{
+		Method:     req.Method,
+		URL:        req.URL.String(),
+		StatusCode: res.StatusCode,
+		Header:     res.Header,
+	}
+	if json.Valid(body) {
+		entry.Body = body
+	} else {
+		entry.RawBody = body
+	}
+
+	data, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	path, err := cassettePath(t.dir, req)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (t *replayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	path, err := cassettePath(t.dir, req)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("no recorded response for %s %s", req.Method, req.URL)
+	}
+
+	entry := cassette{}
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, fmt.Errorf("reading cassette for %s: %w", req.URL, err)
+	}
+
+	body := []byte(entry.Body)
+	if entry.RawBody != nil {
+		body = entry.RawBody
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", entry.StatusCode, http.StatusText(entry.StatusCode)),
+		StatusCode:    entry.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        entry.Header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+var unsafeFileChars = regexp.MustCompile(`[^a-zA-Z0-9-]+`)
+
+// cassettePath names the cassette after the request so recordings stay easy
+// to find, e.g. GET https://pokeapi.co/api/v2/pokemon/pikachu becomes
+// get_pokeapi-co_api_v2_pokemon_pikachu_<hash>.json. The readable part
+// loses punctuation and the query, so the hash, of the method, the whole
+// URL and the body, is what keeps different requests apart.
+func cassettePath(dir string, req *http.Request) (string, error) {
+	sum := sha256.New()
+	fmt.Fprintf(sum, "%s %s\n", req.Method, req.URL.String())
+
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return "", err
+		}
+		_, err = io.Copy(sum, body)
+		body.Close()
+		if err != nil {
+			return "", err
+		}
+	}
+
+	name := strings.ReplaceAll(req.URL.Host, ".", "-") + req.URL.Path
+	name = strings.Trim(unsafeFileChars.ReplaceAllString(name, "_"), "_")
+	hash := hex.EncodeToString(sum.Sum(nil)[:6])
+
+	return filepath.Join(dir, strings.ToLower(req.Method)+"_"+name+"_"+hash+".json"), nil
+}
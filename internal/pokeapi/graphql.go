@@ -0,0 +1,165 @@
+package pokeapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const GraphQLURL = "https://beta.pokeapi.co/graphql/v1beta"
+
+// GraphQLClient uses PokeAPI's GraphQL endpoint for lookups that would take
+// several REST requests. Everything else goes through the embedded REST
+// client, which also provides the caches.
+type GraphQLClient struct {
+	*Client
+	endpoint string
+}
+
+func NewGraphQLClient(rest *Client, endpoint string) *GraphQLClient {
+	return &GraphQLClient{
+		Client:   rest,
+		endpoint: endpoint,
+	}
+}
+
+const pokemonProfileQuery = `query pokemonProfile($name: String!) {
+  pokemon_v2_pokemon(where: {name: {_eq: $name}}, limit: 1) {
+    name
+    pokemon_v2_pokemontypes(order_by: {slot: asc}) {
+      pokemon_v2_type { name }
+    }
+    pokemon_v2_pokemonspecy {
+      name
+      pokemon_v2_generation { name }
+      pokemon_v2_pokemonspeciesnames(where: {pokemon_v2_language: {name: {_eq: "en"}}}) {
+        genus
+      }
+      pokemon_v2_pokemonspeciesflavortexts(where: {pokemon_v2_language: {name: {_eq: "en"}}}, limit: 1) {
+        flavor_text
+      }
+      pokemon_v2_evolutionchain {
+        pokemon_v2_pokemonspecies(order_by: {order: asc}) { name }
+      }
+    }
+  }
+}`
+
+type pokemonProfileData struct {
+	Pokemon []struct {
+		Name  string `json:"name"`
+		Types []struct {
+			Type struct {
+				Name string `json:"name"`
+			} `json:"pokemon_v2_type"`
+		} `json:"pokemon_v2_pokemontypes"`
+		Species struct {
+			Name       string `json:"name"`
+			Generation struct {
+				Name string `json:"name"`
+			} `json:"pokemon_v2_generation"`
+			Names []struct {
+				Genus string `json:"genus"`
+			} `json:"pokemon_v2_pokemonspeciesnames"`
+			FlavorTexts []struct {
+				FlavorText string `json:"flavor_text"`
+			} `json:"pokemon_v2_pokemonspeciesflavortexts"`
+			EvolutionChain struct {
+				Species []struct {
+					Name string `json:"name"`
+				} `json:"pokemon_v2_pokemonspecies"`
+			} `json:"pokemon_v2_evolutionchain"`
+		} `json:"pokemon_v2_pokemonspecy"`
+	} `json:"pokemon_v2_pokemon"`
+}
+
+func (c *GraphQLClient) GetPokemonProfile(ctx context.Context, name string) (PokemonProfile, error) {
+	data := pokemonProfileData{}
+	if err := c.query(ctx, "pokemonProfile", pokemonProfileQuery, map[string]any{"name": name}, &data); err != nil {
+		return PokemonProfile{}, err
+	}
+	if len(data.Pokemon) == 0 {
+		return PokemonProfile{}, ErrNotFound
+	}
+
+	pokemon := data.Pokemon[0]
+	profile := PokemonProfile{
+		Name:       pokemon.Name,
+		Species:    pokemon.Species.Name,
+		Generation: pokemon.Species.Generation.Name,
+	}
+
+	if len(pokemon.Species.Names) > 0 {
+		profile.Genus = pokemon.Species.Names[0].Genus
+	}
+	if len(pokemon.Species.FlavorTexts) > 0 {
+		profile.FlavorText = cleanFlavorText(pokemon.Species.FlavorTexts[0].FlavorText)
+	}
+	for _, t := range pokemon.Types {
+		profile.Types = append(profile.Types, t.Type.Name)
+	}
+	for _, s := range pokemon.Species.EvolutionChain.Species {
+		profile.Evolutions = append(profile.Evolutions, s.Name)
+	}
+
+	return profile, nil
+}
+
+// query runs a GraphQL operation and decodes its data into v. Successful
+// results are cached under the endpoint, operation and variables.
+func (c *GraphQLClient) query(ctx context.Context, operation, query string, variables map[string]any, v any) error {
+	body, err := json.Marshal(map[string]any{
+		"operationName": operation,
+		"query":         query,
+		"variables":     variables,
+	})
+	if err != nil {
+		return err
+	}
+
+	key := c.endpoint + "#" + string(body)
+	data, err := c.cached(key, func() ([]byte, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+
+		res, err := c.do(req)
+		if err != nil {
+			return nil, err
+		}
+
+		var envelope struct {
+			Data   json.RawMessage `json:"data"`
+			Errors []struct {
+				Message string `json:"message"`
+			} `json:"errors"`
+		}
+		if err := json.Unmarshal(res, &envelope); err != nil {
+			return nil, fmt.Errorf("decoding %s response: %w", operation, err)
+		}
+		if len(envelope.Errors) > 0 {
+			var messages []string
+			for _, e := range envelope.Errors {
+				messages = append(messages, e.Message)
+			}
+			return nil, errors.New("graphql: " + strings.Join(messages, "; "))
+		}
+
+		return envelope.Data, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("decoding %s response: %w", operation, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,33 @@
+package pokeapi
+
+import (
+	"context"
+	"strconv"
+)
+
+// nameIndexLimit is larger than any resource list, so one request returns
+// every name in it.
+const nameIndexLimit = 100000
+
+// ResourceNames returns the name of every resource in a list, such as every
+// Pokemon or location area. The whole list is fetched with one request and
+// cached like any other response; Sync stores it too so it works offline.
+func (c *Client) ResourceNames(resource string) ([]string, error) {
+	url := c.nameIndexURL(resource)
+
+	list := ResourceList{}
+	if err := c.getJSON(context.Background(), url, &list); err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(list.Results))
+	for _, result := range list.Results {
+		names = append(names, result.Name)
+	}
+
+	return names, nil
+}
+
+func (c *Client) nameIndexURL(resource string) string {
+	return c.resourceURL(resource, "") + "?offset=0&limit=" + strconv.Itoa(nameIndexLimit)
+}
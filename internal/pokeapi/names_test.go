@@ -0,0 +1,26 @@
+package pokeapi
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestResourceNames(t *testing.T) {
+	client, _, server := newSyncClient(t)
+
+	names, err := client.ResourceNames("location-area")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(names) != 25 || !slices.Contains(names, "great-marsh-area-2") {
+		t.Errorf("expected all 25 location areas, got %d: %v", len(names), names)
+	}
+
+	// The index is one request, cached afterwards.
+	if _, err := client.ResourceNames("location-area"); err != nil {
+		t.Fatal(err)
+	}
+	if n := server.Requests("/location-area/"); n != 1 {
+		t.Errorf("expected one list request, got %d", n)
+	}
+}
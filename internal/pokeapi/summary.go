@@ -0,0 +1,50 @@
+package pokeapi
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// PokemonSummary is the part of a Pokemon that catching and inspecting need.
+// Decoding it skips the large sprite tree and move list.
+type PokemonSummary struct {
+	ID             int    `json:"id"`
+	Name           string `json:"name"`
+	BaseExperience int    `json:"base_experience"`
+	Height         int    `json:"height"`
+	Weight         int    `json:"weight"`
+	Species        struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+	} `json:"species"`
+	Stats []struct {
+		BaseStat int `json:"base_stat"`
+		Stat     struct {
+			Name string `json:"name"`
+		} `json:"stat"`
+	} `json:"stats"`
+	Types []struct {
+		Slot int `json:"slot"`
+		Type struct {
+			Name string `json:"name"`
+		} `json:"type"`
+	} `json:"types"`
+}
+
+func (c *Client) GetPokemonSummary(name string) (PokemonSummary, error) {
+	return c.getPokemonSummary(context.Background(), name)
+}
+
+func (c *Client) getPokemonSummary(ctx context.Context, name string) (PokemonSummary, error) {
+	body, err := c.get(ctx, c.resourceURL("pokemon", name))
+	if err != nil {
+		return PokemonSummary{}, err
+	}
+
+	summary := PokemonSummary{}
+	if err := json.Unmarshal(body, &summary); err != nil {
+		return PokemonSummary{}, err
+	}
+
+	return summary, nil
+}
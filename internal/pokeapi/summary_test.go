@@ -0,0 +1,69 @@
+package pokeapi
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+)
+
+// testdata/pikachu.json is a full-size Pokemon response: every sprite
+// generation and over a hundred moves, each learnable in many games.
+func readPikachu(tb testing.TB) []byte {
+	tb.Helper()
+
+	body, err := os.ReadFile("testdata/pikachu.json")
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	return body
+}
+
+func TestPokemonSummaryMatchesDetails(t *testing.T) {
+	body := readPikachu(t)
+
+	details := PokemonDetails{}
+	if err := json.Unmarshal(body, &details); err != nil {
+		t.Fatal(err)
+	}
+	summary := PokemonSummary{}
+	if err := json.Unmarshal(body, &summary); err != nil {
+		t.Fatal(err)
+	}
+
+	if summary.Name != details.Name || summary.BaseExperience != details.BaseExperience ||
+		summary.Height != details.Height || summary.Weight != details.Weight {
+		t.Errorf("summary %+v does not match details", summary)
+	}
+	if len(summary.Stats) != len(details.Stats) || len(summary.Types) != len(details.Types) {
+		t.Errorf("expected %d stats and %d types, got %d and %d", len(details.Stats), len(details.Types), len(summary.Stats), len(summary.Types))
+	}
+}
+
+// Catching a Pokemon used to decode the full PokemonDetails; it now decodes
+// a PokemonSummary.
+func BenchmarkDecodePokemonDetails(b *testing.B) {
+	body := readPikachu(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		details := PokemonDetails{}
+		if err := json.Unmarshal(body, &details); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDecodePokemonSummary(b *testing.B) {
+	body := readPikachu(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		summary := PokemonSummary{}
+		if err := json.Unmarshal(body, &summary); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
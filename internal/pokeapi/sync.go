@@ -0,0 +1,178 @@
+package pokeapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// SyncResources are the resource lists mirrored by Sync when none are given.
+// Syncing pokemon-species also mirrors each species' evolution chain.
+var SyncResources = []string{"location-area", "pokemon", "pokemon-species", "type", "move"}
+
+type SyncProgress struct {
+	Resource string
+	Done     int
+	Total    int
+}
+
+// Sync mirrors every page of each resource list, the list's name index, and
+// every resource named in the list, into the disk cache using at most workers
+// concurrent requests. Entries already on disk are skipped, so an interrupted
+// sync picks up where it left off when run again. progress is called after
+// each resource.
+func (c *Client) Sync(resources []string, workers int, progress func(SyncProgress)) error {
+	if c.disk == nil {
+		return errors.New("sync requires an on-disk cache")
+	}
+	if workers < 1 {
+		workers = 1
+	}
+
+	for _, resource := range resources {
+		names, err := c.syncList(resource)
+		if err != nil {
+			return fmt.Errorf("listing %s: %w", resource, err)
+		}
+		if _, err := c.store(c.nameIndexURL(resource)); err != nil {
+			return fmt.Errorf("listing %s: %w", resource, err)
+		}
+
+		if err := c.syncResources(resource, names, workers, progress); err != nil {
+			return fmt.Errorf("syncing %s: %w", resource, err)
+		}
+	}
+
+	return nil
+}
+
+// syncList walks every page of a resource list, storing each page, and
+// returns the names of all resources in the list.
+func (c *Client) syncList(resource string) ([]string, error) {
+	var names []string
+
+	pageURL := c.resourceURL(resource, "")
+	for pageURL != "" {
+		body, err := c.store(pageURL)
+		if err != nil {
+			return nil, err
+		}
+
+		list := ResourceList{}
+		if err := json.Unmarshal(body, &list); err != nil {
+			return nil, fmt.Errorf("decoding %s: %w", pageURL, err)
+		}
+
+		for _, result := range list.Results {
+			names = append(names, result.Name)
+		}
+
+		pageURL = ""
+		if list.Next != nil {
+			pageURL = *list.Next
+		}
+	}
+
+	return names, nil
+}
+
+func (c *Client) syncResources(resource string, names []string, workers int, progress func(SyncProgress)) error {
+	jobs := make(chan string)
+	errs := make(chan error, workers)
+	var wg sync.WaitGroup
+
+	var mutex sync.Mutex
+	done := 0
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for name := range jobs {
+				if err := c.syncResource(resource, name); err != nil {
+					errs <- fmt.Errorf("%s: %w", name, err)
+					return
+				}
+
+				mutex.Lock()
+				done++
+				if progress != nil {
+					progress(SyncProgress{Resource: resource, Done: done, Total: len(names)})
+				}
+				mutex.Unlock()
+			}
+		}()
+	}
+
+	var err error
+dispatch:
+	for _, name := range names {
+		select {
+		case jobs <- name:
+		case err = <-errs:
+			break dispatch
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if err == nil {
+		select {
+		case err = <-errs:
+		default:
+		}
+	}
+
+	return err
+}
+
+// syncResource stores one resource in the disk cache. Evolution chains have
+// no names to list them by, so a species' chain is stored along with it.
+func (c *Client) syncResource(resource, name string) error {
+	url := c.resourceURL(resource, name)
+	if resource != "pokemon-species" {
+		if c.disk.Has(url) {
+			return nil
+		}
+		_, err := c.store(url)
+		return err
+	}
+
+	body, err := c.store(url)
+	if err != nil {
+		return err
+	}
+	species := PokemonSpecies{}
+	if err := json.Unmarshal(body, &species); err != nil {
+		return fmt.Errorf("decoding %s: %w", url, err)
+	}
+	if chain := species.EvolutionChain.URL; chain != "" && !c.disk.Has(chain) {
+		if _, err := c.store(chain); err != nil {
+			return fmt.Errorf("evolution chain: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// store makes sure url is in the disk cache and returns its body. Unlike get it
+// bypasses the in-memory cache so a full sync does not hold the whole dataset
+// in memory.
+func (c *Client) store(url string) ([]byte, error) {
+	if val, ok := c.disk.Get(url); ok {
+		return val, nil
+	}
+
+	body, err := c.fetch(context.Background(), url)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.disk.Add(url, body); err != nil {
+		return nil, err
+	}
+
+	return body, nil
+}
@@ -0,0 +1,107 @@
+package pokeapi
+
+import (
+	"context"
+	"pokedexcli/internal/pokeapitest"
+	"pokedexcli/internal/pokecache"
+	"testing"
+	"time"
+)
+
+func newSyncClient(t *testing.T) (*Client, *pokecache.DiskCache, *pokeapitest.Server) {
+	t.Helper()
+
+	server := pokeapitest.NewServer()
+	t.Cleanup(server.Close)
+
+	disk, err := pokecache.NewDiskCache(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := NewClient(time.Minute, disk)
+	client.SetBaseURL(server.BaseURL())
+
+	return client, disk, server
+}
+
+func TestSync(t *testing.T) {
+	client, disk, server := newSyncClient(t)
+
+	var last SyncProgress
+	if err := client.Sync([]string{"location-area"}, 4, func(p SyncProgress) { last = p }); err != nil {
+		t.Fatal(err)
+	}
+
+	if last.Done != 25 || last.Total != 25 {
+		t.Errorf("expected final progress 25/25, got %d/%d", last.Done, last.Total)
+	}
+	for _, url := range []string{
+		server.BaseURL() + "/location-area/",
+		server.BaseURL() + "/location-area/?offset=20&limit=20",
+		server.BaseURL() + "/location-area/?offset=0&limit=100000",
+		server.BaseURL() + "/location-area/canalave-city-area",
+		server.BaseURL() + "/location-area/great-marsh-area-2",
+	} {
+		if !disk.Has(url) {
+			t.Errorf("expected %s to be stored on disk", url)
+		}
+	}
+
+	// A second sync is served entirely from disk.
+	if err := client.Sync([]string{"location-area"}, 4, nil); err != nil {
+		t.Fatal(err)
+	}
+	if n := server.Requests("/location-area/canalave-city-area"); n != 1 {
+		t.Errorf("expected one request for canalave-city-area, got %d", n)
+	}
+}
+
+func TestSyncResumesAfterFailure(t *testing.T) {
+	client, disk, server := newSyncClient(t)
+
+	server.SetFault("/pokemon/pikachu", 503)
+	if err := client.Sync([]string{"pokemon"}, 1, nil); err == nil {
+		t.Fatal("expected the injected fault to fail the sync")
+	}
+
+	server.ClearFaults()
+	if err := client.Sync([]string{"pokemon"}, 1, nil); err != nil {
+		t.Fatal(err)
+	}
+	if !disk.Has(server.BaseURL() + "/pokemon/pikachu") {
+		t.Errorf("expected pikachu to be stored after resuming")
+	}
+	if n := server.Requests("/pokemon/bulbasaur"); n != 1 {
+		t.Errorf("expected bulbasaur to be fetched once across both runs, got %d", n)
+	}
+}
+
+func TestSyncStoresEvolutionChains(t *testing.T) {
+	client, disk, server := newSyncClient(t)
+
+	if err := client.Sync([]string{"pokemon", "pokemon-species"}, 2, nil); err != nil {
+		t.Fatal(err)
+	}
+	if !disk.Has(server.BaseURL() + "/evolution-chain/10/") {
+		t.Errorf("expected pikachu's evolution chain to be stored on disk")
+	}
+
+	// A synced profile needs no network.
+	server.Close()
+	profile, err := client.GetPokemonProfile(context.Background(), "pikachu")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(profile.Evolutions) != 3 {
+		t.Errorf("expected pikachu's evolutions, got %v", profile.Evolutions)
+	}
+}
+
+func TestSyncUnknownResource(t *testing.T) {
+	client, _, _ := newSyncClient(t)
+
+	if err := client.Sync([]string{"nope"}, 2, nil); err == nil {
+		t.Errorf("expected an error for an unknown resource")
+	}
+}
@@ -0,0 +1,169 @@
+package pokeapitest
+
+import (
+	"encoding/json"
+	"net/http"
+	"path"
+)
+
+// GraphQLPath is where the server answers GraphQL queries.
+const GraphQLPath = "/graphql/v1beta"
+
+// GraphQLURL is the GraphQL endpoint to configure clients with.
+func (s *Server) GraphQLURL() string {
+	return s.URL + GraphQLPath
+}
+
+// serveGraphQL is a stub of PokeAPI's GraphQL endpoint. It only understands
+// the operations the client sends and answers them from the REST fixtures, so
+// both backends see the same data.
+func (s *Server) serveGraphQL(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		OperationName string         `json:"operationName"`
+		Variables     map[string]any `json:"variables"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var data any
+	switch req.OperationName {
+	case "pokemonProfile":
+		name, _ := req.Variables["name"].(string)
+		data = s.pokemonProfile(name)
+	default:
+		s.writeGraphQL(w, map[string]any{
+			"errors": []map[string]any{{"message": "unknown operation " + req.OperationName}},
+		})
+		return
+	}
+
+	s.writeGraphQL(w, map[string]any{"data": data})
+}
+
+func (s *Server) pokemonProfile(name string) map[string]any {
+	found := []any{}
+	result := map[string]any{"pokemon_v2_pokemon": found}
+
+	var pokemon struct {
+		Name  string `json:"name"`
+		Types []struct {
+			Type struct {
+				Name string `json:"name"`
+			} `json:"type"`
+		} `json:"types"`
+		Species struct {
+			Name string `json:"name"`
+		} `json:"species"`
+	}
+	if !s.decodeFixture("pokemon", name, &pokemon) {
+		return result
+	}
+
+	var species struct {
+		Name       string `json:"name"`
+		Generation struct {
+			Name string `json:"name"`
+		} `json:"generation"`
+		Genera []struct {
+			Genus    string `json:"genus"`
+			Language struct {
+				Name string `json:"name"`
+			} `json:"language"`
+		} `json:"genera"`
+		FlavorTextEntries []struct {
+			FlavorText string `json:"flavor_text"`
+			Language   struct {
+				Name string `json:"name"`
+			} `json:"language"`
+		} `json:"flavor_text_entries"`
+		EvolutionChain struct {
+			URL string `json:"url"`
+		} `json:"evolution_chain"`
+	}
+	if !s.decodeFixture("pokemon-species", pokemon.Species.Name, &species) {
+		return result
+	}
+
+	type link struct {
+		Species struct {
+			Name string `json:"name"`
+		} `json:"species"`
+		EvolvesTo []link `json:"evolves_to"`
+	}
+	var chain struct {
+		Chain link `json:"chain"`
+	}
+	s.decodeFixture("evolution-chain", path.Base(species.EvolutionChain.URL), &chain)
+
+	types := []any{}
+	for _, t := range pokemon.Types {
+		types = append(types, map[string]any{"pokemon_v2_type": map[string]any{"name": t.Type.Name}})
+	}
+	names := []any{}
+	for _, g := range species.Genera {
+		if g.Language.Name == "en" {
+			names = append(names, map[string]any{"genus": g.Genus})
+		}
+	}
+	flavorTexts := []any{}
+	for _, f := range species.FlavorTextEntries {
+		if f.Language.Name == "en" {
+			flavorTexts = append(flavorTexts, map[string]any{"flavor_text": f.FlavorText})
+			break
+		}
+	}
+	chainSpecies := []any{}
+	stage := []link{chain.Chain}
+	for len(stage) > 0 && stage[0].Species.Name != "" {
+		var next []link
+		for _, l := range stage {
+			chainSpecies = append(chainSpecies, map[string]any{"name": l.Species.Name})
+			next = append(next, l.EvolvesTo...)
+		}
+		stage = next
+	}
+
+	result["pokemon_v2_pokemon"] = append(found, map[string]any{
+		"name":                    pokemon.Name,
+		"pokemon_v2_pokemontypes": types,
+		"pokemon_v2_pokemonspecy": map[string]any{
+			"name":                                 species.Name,
+			"pokemon_v2_generation":                map[string]any{"name": species.Generation.Name},
+			"pokemon_v2_pokemonspeciesnames":       names,
+			"pokemon_v2_pokemonspeciesflavortexts": flavorTexts,
+			"pokemon_v2_evolutionchain": map[string]any{
+				"pokemon_v2_pokemonspecies": chainSpecies,
+			},
+		},
+	})
+
+	return result
+}
+
+// decodeFixture decodes the fixture for a resource by name or id into v and
+// reports whether it exists.
+func (s *Server) decodeFixture(kind, name string, v any) bool {
+	res, ok := s.find(kind, name)
+	if !ok {
+		return false
+	}
+
+	return json.Unmarshal(res.body, v) == nil
+}
+
+func (s *Server) writeGraphQL(w http.ResponseWriter, v any) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.write(w, body)
+}
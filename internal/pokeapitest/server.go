@@ -0,0 +1,259 @@
+// Package pokeapitest serves fixture PokeAPI responses from a local HTTP
+// server so commands and clients can be tested without the network.
+package pokeapitest
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+//go:embed fixtures
+var fixtures embed.FS
+
+// fixtureBaseURL is the API root used inside fixture files. It is rewritten to
+// the server's own URL when responses are served.
+const fixtureBaseURL = "https://pokeapi.co/api/v2"
+
+const defaultLimit = 20
+
+type Server struct {
+	*httptest.Server
+
+	resources map[string][]resource
+
+	mutex    sync.Mutex
+	latency  time.Duration
+	faults   map[string]int
+	requests map[string]int
+}
+
+type resource struct {
+	id   int
+	name string
+	body []byte
+}
+
+// NewServer starts a server for every resource under fixtures/. Each resource
+// is served at /<resource>/<name-or-id> and listed with pagination at
+// /<resource>/. Call Close when done.
+func NewServer() *Server {
+	server := &Server{
+		resources: loadFixtures(),
+		faults:    make(map[string]int),
+		requests:  make(map[string]int),
+	}
+	server.Server = httptest.NewServer(http.HandlerFunc(server.serve))
+
+	return server
+}
+
+// BaseURL is the API root to configure clients with.
+func (s *Server) BaseURL() string {
+	return s.URL
+}
+
+// SetLatency delays every response by d.
+func (s *Server) SetLatency(d time.Duration) {
+	s.mutex.Lock()
+	s.latency = d
+	s.mutex.Unlock()
+}
+
+// SetFault makes requests for path (e.g. "/pokemon/pikachu") fail with status
+// until ClearFaults is called.
+func (s *Server) SetFault(path string, status int) {
+	s.mutex.Lock()
+	s.faults[path] = status
+	s.mutex.Unlock()
+}
+
+func (s *Server) ClearFaults() {
+	s.mutex.Lock()
+	s.faults = make(map[string]int)
+	s.mutex.Unlock()
+}
+
+// Requests reports how many times path has been requested.
+func (s *Server) Requests(path string) int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.requests[path]
+}
+
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	s.requests[r.URL.Path]++
+	latency := s.latency
+	fault := s.faults[r.URL.Path]
+	s.mutex.Unlock()
+
+	if latency > 0 {
+		time.Sleep(latency)
+	}
+	if fault != 0 {
+		http.Error(w, http.StatusText(fault), fault)
+		return
+	}
+
+	if r.URL.Path == GraphQLPath {
+		s.serveGraphQL(w, r)
+		return
+	}
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	resources, ok := s.resources[parts[0]]
+	if !ok || len(parts) > 2 {
+		http.NotFound(w, r)
+		return
+	}
+
+	if len(parts) == 1 {
+		s.serveList(w, r, parts[0], resources)
+		return
+	}
+
+	res, ok := s.find(parts[0], parts[1])
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	s.write(w, res.body)
+}
+
+// find looks up a resource by name or id.
+func (s *Server) find(kind, name string) (resource, bool) {
+	for _, res := range s.resources[kind] {
+		if res.name == name || strconv.Itoa(res.id) == name {
+			return res, true
+		}
+	}
+
+	return resource{}, false
+}
+
+func (s *Server) serveList(w http.ResponseWriter, r *http.Request, name string, resources []resource) {
+	offset, err := queryInt(r, "offset", 0)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	limit, err := queryInt(r, "limit", defaultLimit)
+	if err == nil && limit == 0 {
+		// Following next would never get past offset.
+		err = errors.New("invalid limit: must be at least 1")
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	type result struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+	}
+	list := struct {
+		Count    int      `json:"count"`
+		Next     *string  `json:"next"`
+		Previous *string  `json:"previous"`
+		Results  []result `json:"results"`
+	}{
+		Count:   len(resources),
+		Results: []result{},
+	}
+
+	listURL := fixtureBaseURL + "/" + name + "/"
+	if offset+limit < len(resources) {
+		next := fmt.Sprintf("%s?offset=%d&limit=%d", listURL, offset+limit, limit)
+		list.Next = &next
+	}
+	if offset > 0 {
+		previous := fmt.Sprintf("%s?offset=%d&limit=%d", listURL, max(offset-limit, 0), limit)
+		list.Previous = &previous
+	}
+
+	for i := offset; i < offset+limit && i < len(resources); i++ {
+		list.Results = append(list.Results, result{
+			Name: resources[i].name,
+			URL:  fmt.Sprintf("%s%d/", listURL, resources[i].id),
+		})
+	}
+
+	body, err := json.Marshal(list)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.write(w, body)
+}
+
+func (s *Server) write(w http.ResponseWriter, body []byte) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Write(bytes.ReplaceAll(body, []byte(fixtureBaseURL), []byte(s.URL)))
+}
+
+func queryInt(r *http.Request, key string, fallback int) (int, error) {
+	val := r.URL.Query().Get(key)
+	if val == "" {
+		return fallback, nil
+	}
+
+	n, err := strconv.Atoi(val)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid %s: %q", key, val)
+	}
+
+	return n, nil
+}
+
+// loadFixtures reads fixtures/<resource>/<name>.json, ordering each resource
+// list by id like the real API does.
+func loadFixtures() map[string][]resource {
+	resources := make(map[string][]resource)
+
+	err := fs.WalkDir(fixtures, "fixtures", func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || path.Ext(p) != ".json" {
+			return err
+		}
+
+		body, err := fixtures.ReadFile(p)
+		if err != nil {
+			return err
+		}
+
+		var header struct {
+			ID   int    `json:"id"`
+			Name string `json:"name"`
+		}
+		if err := json.Unmarshal(body, &header); err != nil {
+			return fmt.Errorf("%s: %w", p, err)
+		}
+
+		kind := path.Base(path.Dir(p))
+		resources[kind] = append(resources[kind], resource{id: header.ID, name: header.Name, body: body})
+		return nil
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	for _, list := range resources {
+		sort.Slice(list, func(i, j int) bool { return list[i].id < list[j].id })
+	}
+
+	return resources
+}
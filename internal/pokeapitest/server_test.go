@@ -0,0 +1,131 @@
+package pokeapitest
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+)
+
+type list struct {
+	Count    int     `json:"count"`
+	Next     *string `json:"next"`
+	Previous *string `json:"previous"`
+	Results  []struct {
+		Name string `json:"name"`
+	} `json:"results"`
+}
+
+func getList(t *testing.T, url string) list {
+	t.Helper()
+
+	res, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", res.StatusCode)
+	}
+
+	l := list{}
+	if err := json.NewDecoder(res.Body).Decode(&l); err != nil {
+		t.Fatal(err)
+	}
+
+	return l
+}
+
+func TestListPagination(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	first := getList(t, server.BaseURL()+"/location-area/")
+	if len(first.Results) != defaultLimit {
+		t.Fatalf("expected %d results, got %d", defaultLimit, len(first.Results))
+	}
+	if first.Results[0].Name != "canalave-city-area" {
+		t.Errorf("expected lists to be ordered by id, got %s first", first.Results[0].Name)
+	}
+	if first.Previous != nil {
+		t.Errorf("expected no previous page, got %s", *first.Previous)
+	}
+	if first.Next == nil || *first.Next != server.BaseURL()+"/location-area/?offset=20&limit=20" {
+		t.Fatalf("unexpected next page: %v", first.Next)
+	}
+
+	second := getList(t, *first.Next)
+	if len(second.Results) != first.Count-defaultLimit {
+		t.Errorf("expected %d results, got %d", first.Count-defaultLimit, len(second.Results))
+	}
+	if second.Next != nil {
+		t.Errorf("expected no next page, got %s", *second.Next)
+	}
+	if second.Previous == nil || *second.Previous != server.BaseURL()+"/location-area/?offset=0&limit=20" {
+		t.Errorf("unexpected previous page: %v", second.Previous)
+	}
+}
+
+func TestResourceLookup(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	cases := []struct {
+		path   string
+		status int
+	}{
+		{path: "/pokemon/pikachu", status: http.StatusOK},
+		{path: "/pokemon/25", status: http.StatusOK},
+		{path: "/pokemon/missingno", status: http.StatusNotFound},
+		{path: "/berry/cheri", status: http.StatusNotFound},
+		{path: "/pokemon/?limit=0", status: http.StatusBadRequest},
+		{path: "/pokemon/?limit=-1", status: http.StatusBadRequest},
+	}
+
+	for _, c := range cases {
+		res, err := http.Get(server.BaseURL() + c.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+
+		if res.StatusCode != c.status {
+			t.Errorf("%s: expected status %d, got %d", c.path, c.status, res.StatusCode)
+		}
+	}
+
+	if server.Requests("/pokemon/pikachu") != 1 {
+		t.Errorf("expected one request for pikachu, got %d", server.Requests("/pokemon/pikachu"))
+	}
+}
+
+func TestFaultsAndLatency(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	server.SetFault("/pokemon/pikachu", http.StatusServiceUnavailable)
+	res, err := http.Get(server.BaseURL() + "/pokemon/pikachu")
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("expected injected fault, got status %d", res.StatusCode)
+	}
+
+	server.ClearFaults()
+	server.SetLatency(20 * time.Millisecond)
+	start := time.Now()
+	res, err = http.Get(server.BaseURL() + "/pokemon/pikachu")
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		t.Errorf("expected status 200 after clearing faults, got %d", res.StatusCode)
+	}
+	if time.Since(start) < 20*time.Millisecond {
+		t.Errorf("expected response to be delayed")
+	}
+}
@@ -0,0 +1,47 @@
+package pokecache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"pokedexcli/internal/atomicfile"
+)
+
+// DiskCache stores response bodies as files in a directory so they survive
+// between sessions. Keys are hashed into file names.
+type DiskCache struct {
+	dir string
+}
+
+func NewDiskCache(dir string) (*DiskCache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	return &DiskCache{dir: dir}, nil
+}
+
+func (cache *DiskCache) Add(key string, val []byte) error {
+	return atomicfile.Write(cache.path(key), val)
+}
+
+func (cache *DiskCache) Get(key string) ([]byte, bool) {
+	val, err := os.ReadFile(cache.path(key))
+	if err != nil {
+		return nil, false
+	}
+
+	return val, true
+}
+
+func (cache *DiskCache) Has(key string) bool {
+	_, err := os.Stat(cache.path(key))
+	return err == nil
+}
+
+func (cache *DiskCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	name := hex.EncodeToString(sum[:])
+	return filepath.Join(cache.dir, name[:2], name)
+}
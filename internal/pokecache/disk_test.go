@@ -0,0 +1,70 @@
+package pokecache
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestDiskAddGet(t *testing.T) {
+	cases := []struct {
+		key string
+		val []byte
+	}{
+		{
+			key: "https://example.com",
+			val: []byte("testdata"),
+		},
+		{
+			key: "https://example.com/path",
+			val: []byte("moretestdata"),
+		},
+	}
+
+	for i, c := range cases {
+		t.Run(fmt.Sprintf("Test case %v", i), func(t *testing.T) {
+			cache, err := NewDiskCache(t.TempDir())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if cache.Has(c.key) {
+				t.Errorf("expected key to be missing before Add")
+				return
+			}
+			if err := cache.Add(c.key, c.val); err != nil {
+				t.Fatal(err)
+			}
+
+			val, ok := cache.Get(c.key)
+			if !ok {
+				t.Errorf("expected to find key")
+				return
+			}
+			if string(val) != string(c.val) {
+				t.Errorf("expected to find value")
+				return
+			}
+		})
+	}
+}
+
+func TestDiskPersistsAcrossInstances(t *testing.T) {
+	dir := t.TempDir()
+
+	first, err := NewDiskCache(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := first.Add("https://example.com", []byte("testdata")); err != nil {
+		t.Fatal(err)
+	}
+
+	second, err := NewDiskCache(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	val, ok := second.Get("https://example.com")
+	if !ok || string(val) != "testdata" {
+		t.Errorf("expected entry written by another instance to be found")
+	}
+}
@@ -0,0 +1,91 @@
+// Package render writes command results as JSON, YAML, CSV or an aligned
+// table.
+package render
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v3"
+)
+
+type Format string
+
+const (
+	// Text is the commands' own human-readable output; Write does not
+	// handle it.
+	Text  Format = "text"
+	JSON  Format = "json"
+	YAML  Format = "yaml"
+	CSV   Format = "csv"
+	Table Format = "table"
+)
+
+// Formats lists every format, in the order help shows them.
+var Formats = []Format{Text, JSON, YAML, CSV, Table}
+
+// ParseFormat returns the format called name.
+func ParseFormat(name string) (Format, error) {
+	for _, format := range Formats {
+		if string(format) == strings.ToLower(name) {
+			return format, nil
+		}
+	}
+
+	names := make([]string, len(Formats))
+	for i, format := range Formats {
+		names[i] = string(format)
+	}
+	return "", fmt.Errorf("unknown output format %q: expected one of %s", name, strings.Join(names, ", "))
+}
+
+// Tabular is a result that can be shown as rows of columns as well as
+// encoded whole.
+type Tabular interface {
+	Header() []string
+	Rows() [][]string
+}
+
+// Write renders v to w. JSON and YAML encode v itself, so its json and yaml
+// field tags define the schema; CSV and Table write its header and rows.
+func Write(w io.Writer, format Format, v Tabular) error {
+	switch format {
+	case JSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	case YAML:
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(v); err != nil {
+			return err
+		}
+		return enc.Close()
+	case CSV:
+		cw := csv.NewWriter(w)
+		if err := cw.Write(v.Header()); err != nil {
+			return err
+		}
+		if err := cw.WriteAll(v.Rows()); err != nil {
+			return err
+		}
+		return cw.Error()
+	case Table:
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		header := make([]string, len(v.Header()))
+		for i, column := range v.Header() {
+			header[i] = strings.ToUpper(column)
+		}
+		fmt.Fprintln(tw, strings.Join(header, "\t"))
+		for _, row := range v.Rows() {
+			fmt.Fprintln(tw, strings.Join(row, "\t"))
+		}
+		return tw.Flush()
+	default:
+		return fmt.Errorf("cannot render %s output", format)
+	}
+}
@@ -0,0 +1,101 @@
+package render
+
+import (
+	"strings"
+	"testing"
+)
+
+type record struct {
+	Name  string   `json:"name" yaml:"name"`
+	Types []string `json:"types" yaml:"types"`
+}
+
+type records []record
+
+func (r records) Header() []string {
+	return []string{"name", "types"}
+}
+
+func (r records) Rows() [][]string {
+	var rows [][]string
+	for _, rec := range r {
+		rows = append(rows, []string{rec.Name, strings.Join(rec.Types, ";")})
+	}
+	return rows
+}
+
+func TestWrite(t *testing.T) {
+	v := records{
+		{Name: "bulbasaur", Types: []string{"grass", "poison"}},
+		{Name: "mr-mime, jr", Types: []string{"psychic"}},
+	}
+
+	cases := []struct {
+		format   Format
+		expected string
+	}{
+		{
+			format: JSON,
+			expected: `[
+  {
+    "name": "bulbasaur",
+    "types": [
+      "grass",
+      "poison"
+    ]
+  },
+  {
+    "name": "mr-mime, jr",
+    "types": [
+      "psychic"
+    ]
+  }
+]
+`,
+		},
+		{
+			format: YAML,
+			expected: `- name: bulbasaur
+  types:
+    - grass
+    - poison
+- name: mr-mime, jr
+  types:
+    - psychic
+`,
+		},
+		{
+			format:   CSV,
+			expected: "name,types\nbulbasaur,grass;poison\n\"mr-mime, jr\",psychic\n",
+		},
+		{
+			format:   Table,
+			expected: "NAME         TYPES\nbulbasaur    grass;poison\nmr-mime, jr  psychic\n",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(string(c.format), func(t *testing.T) {
+			var out strings.Builder
+			if err := Write(&out, c.format, v); err != nil {
+				t.Fatal(err)
+			}
+			if out.String() != c.expected {
+				t.Errorf("expected:\n%s\ngot:\n%s", c.expected, out.String())
+			}
+		})
+	}
+
+	if err := Write(&strings.Builder{}, Text, v); err == nil {
+		t.Errorf("expected an error for text output")
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	if format, err := ParseFormat("JSON"); err != nil || format != JSON {
+		t.Errorf("expected json, got %q, %v", format, err)
+	}
+	if _, err := ParseFormat("xml"); err == nil || err.Error() != `unknown output format "xml": expected one of text, json, yaml, csv, table` {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,134 @@
+// Package theme colors terminal output with ANSI escape sequences.
+package theme
+
+import (
+	"os"
+	"sort"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// Theme maps style names to ANSI SGR parameters, such as "1;31" for bold
+// red. Styles are named after what they color: "error", "prompt",
+// "location", "count", "pokemon", "heading", "type:<type>" and
+// "stat:<stat>". The basic color names, such as "red", are always
+// available. The zero Theme colors nothing.
+type Theme struct {
+	styles map[string]string
+}
+
+var basicColors = map[string]string{
+	"black":   "30",
+	"red":     "31",
+	"green":   "32",
+	"yellow":  "33",
+	"blue":    "34",
+	"magenta": "35",
+	"cyan":    "36",
+	"white":   "37",
+	"gray":    "90",
+	"bold":    "1",
+	"dim":     "2",
+}
+
+var themes = map[string]map[string]string{
+	"default": {
+		"error":    "31",
+		"prompt":   "1;36",
+		"location": "32",
+		"count":    "33",
+		"pokemon":  "1",
+		"heading":  "1",
+
+		"type:normal":   "37",
+		"type:fire":     "31",
+		"type:water":    "34",
+		"type:grass":    "32",
+		"type:electric": "33",
+		"type:ice":      "96",
+		"type:fighting": "91",
+		"type:poison":   "35",
+		"type:ground":   "33;2",
+		"type:flying":   "94",
+		"type:psychic":  "95",
+		"type:bug":      "92",
+		"type:rock":     "33;2",
+		"type:ghost":    "35;2",
+		"type:dragon":   "34;1",
+		"type:dark":     "90",
+		"type:steel":    "37;2",
+		"type:fairy":    "95",
+
+		"stat:hp":              "32",
+		"stat:attack":          "31",
+		"stat:defense":         "33",
+		"stat:special-attack":  "35",
+		"stat:special-defense": "34",
+		"stat:speed":           "36",
+	},
+	"mono": {
+		"error":    "1",
+		"prompt":   "1",
+		"location": "4",
+		"count":    "1",
+		"pokemon":  "1",
+		"heading":  "1",
+	},
+	"none": nil,
+}
+
+// Names returns the names of the built-in themes.
+func Names() []string {
+	names := make([]string, 0, len(themes))
+	for name := range themes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Get returns the built-in theme called name. When enabled is false, or
+// there is no such theme, the returned theme colors nothing; so does the
+// theme called "none".
+func Get(name string, enabled bool) (*Theme, bool) {
+	styles, ok := themes[name]
+	if !ok || !enabled || styles == nil {
+		return &Theme{}, ok
+	}
+
+	t := &Theme{styles: make(map[string]string)}
+	for style, sgr := range basicColors {
+		t.styles[style] = sgr
+	}
+	for style, sgr := range styles {
+		t.styles[style] = sgr
+	}
+
+	return t, true
+}
+
+// Paint wraps text in the escape sequences for style. Text is returned
+// unchanged if the theme has no such style.
+func (t *Theme) Paint(style, text string) string {
+	sgr, ok := t.styles[style]
+	if !ok || text == "" {
+		return text
+	}
+
+	return "\x1b[" + sgr + "m" + text + "\x1b[0m"
+}
+
+// Enabled reports whether colors should be written to f: it must be a
+// terminal, and NO_COLOR must not be set (see https://no-color.org).
+func Enabled(f *os.File) bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	if strings.EqualFold(os.Getenv("TERM"), "dumb") {
+		return false
+	}
+
+	return term.IsTerminal(int(f.Fd()))
+}
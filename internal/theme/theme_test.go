@@ -0,0 +1,66 @@
+package theme
+
+import (
+	"os"
+	"testing"
+)
+
+func TestPaint(t *testing.T) {
+	colored, ok := Get("default", true)
+	if !ok {
+		t.Fatal("expected the default theme to exist")
+	}
+
+	cases := []struct {
+		theme    *Theme
+		style    string
+		text     string
+		expected string
+	}{
+		{theme: colored, style: "type:fire", text: "fire", expected: "\x1b[31mfire\x1b[0m"},
+		{theme: colored, style: "red", text: "x", expected: "\x1b[31mx\x1b[0m"},
+		{theme: colored, style: "type:shadow", text: "shadow", expected: "shadow"},
+		{theme: colored, style: "error", text: "", expected: ""},
+		{theme: &Theme{}, style: "error", text: "oops", expected: "oops"},
+	}
+
+	for _, c := range cases {
+		if actual := c.theme.Paint(c.style, c.text); actual != c.expected {
+			t.Errorf("Paint(%q, %q): expected %q, got %q", c.style, c.text, c.expected, actual)
+		}
+	}
+}
+
+func TestGet(t *testing.T) {
+	disabled, ok := Get("default", false)
+	if !ok || disabled.Paint("error", "oops") != "oops" {
+		t.Errorf("expected a disabled theme to color nothing")
+	}
+
+	none, ok := Get("none", true)
+	if !ok || none.Paint("red", "x") != "x" {
+		t.Errorf("expected the none theme to color nothing")
+	}
+
+	if _, ok := Get("neon", true); ok {
+		t.Errorf("expected an unknown theme to be reported")
+	}
+}
+
+func TestEnabled(t *testing.T) {
+	f, err := os.CreateTemp(t.TempDir(), "out")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	t.Setenv("NO_COLOR", "")
+	if Enabled(f) {
+		t.Errorf("expected colors to be disabled for a regular file")
+	}
+
+	t.Setenv("NO_COLOR", "1")
+	if Enabled(os.Stdout) {
+		t.Errorf("expected NO_COLOR to disable colors")
+	}
+}
@@ -0,0 +1,318 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// macroParam matches the parameters substituted into macro steps: $1 to $9
+// for single arguments and $@ for all of them.
+var macroParam = regexp.MustCompile(`\$([1-9]|@)`)
+
+func commandAlias(cfg *config, args commandArgs) (result, error) {
+	name := strings.ToLower(args.arg(0))
+
+	switch {
+	case name == "":
+		lines := textResult{}
+		for _, alias := range sortedKeys(cfg.aliases) {
+			lines = append(lines, alias+": "+cfg.aliases[alias])
+		}
+		return lines, nil
+	case deleting(args):
+		if _, ok := cfg.aliases[name]; !ok {
+			return nil, fmt.Errorf("no alias named %s", name)
+		}
+		delete(cfg.aliases, name)
+		return nil, cfg.saveUserCommands()
+	case len(args.positional) == 1:
+		expansion, ok := cfg.aliases[name]
+		if !ok {
+			return nil, fmt.Errorf("no alias named %s", name)
+		}
+		return textResult{name + ": " + expansion}, nil
+	}
+
+	if err := checkUserCommandName(name); err != nil {
+		return nil, err
+	}
+	if _, ok := cfg.macros[name]; ok {
+		return nil, fmt.Errorf("%s is already a macro", name)
+	}
+
+	previous, existed := cfg.aliases[name]
+	cfg.aliases[name] = quoteArgs(args.positional[1:])
+	if err := checkUserCommandCycle(cfg, name); err != nil {
+		if existed {
+			cfg.aliases[name] = previous
+		} else {
+			delete(cfg.aliases, name)
+		}
+		return nil, err
+	}
+
+	return nil, cfg.saveUserCommands()
+}
+
+func commandMacro(cfg *config, args commandArgs) (result, error) {
+	name := strings.ToLower(args.arg(0))
+
+	switch {
+	case name == "":
+		lines := textResult{}
+		for _, macro := range sortedKeys(cfg.macros) {
+			lines = append(lines, macro+": "+strings.Join(cfg.macros[macro], "; "))
+		}
+		return lines, nil
+	case deleting(args):
+		if _, ok := cfg.macros[name]; !ok {
+			return nil, fmt.Errorf("no macro named %s", name)
+		}
+		delete(cfg.macros, name)
+		return nil, cfg.saveUserCommands()
+	case len(args.positional) == 1:
+		steps, ok := cfg.macros[name]
+		if !ok {
+			return nil, fmt.Errorf("no macro named %s", name)
+		}
+		return textResult{name + ": " + strings.Join(steps, "; ")}, nil
+	}
+
+	if err := checkUserCommandName(name); err != nil {
+		return nil, err
+	}
+	if _, ok := cfg.aliases[name]; ok {
+		return nil, fmt.Errorf("%s is already an alias", name)
+	}
+	for _, step := range args.positional[1:] {
+		if _, err := splitCommandLine(step); err != nil {
+			return nil, fmt.Errorf("step %q: %w", step, err)
+		}
+	}
+
+	previous, existed := cfg.macros[name]
+	cfg.macros[name] = slices.Clone(args.positional[1:])
+	if err := checkUserCommandCycle(cfg, name); err != nil {
+		if existed {
+			cfg.macros[name] = previous
+		} else {
+			delete(cfg.macros, name)
+		}
+		return nil, err
+	}
+
+	return nil, cfg.saveUserCommands()
+}
+
+// deleting reports whether alias or macro was asked to delete the named
+// one, with --delete given either before the name or as the only word after
+// it.
+func deleting(args commandArgs) bool {
+	if args.boolFlag("delete") {
+		return true
+	}
+	return len(args.positional) == 2 && (args.positional[1] == "--delete" || args.positional[1] == "-delete")
+}
+
+// checkUserCommandName makes sure a new alias or macro has a name that can
+// be typed and does not hide a built-in command.
+func checkUserCommandName(name string) error {
+	if strings.ContainsAny(name, " \t\"'\\$!|;&#") {
+		return fmt.Errorf("%q is not a valid name", name)
+	}
+	if _, ok := lookupCommand(name); ok {
+		return fmt.Errorf("%s is a built-in command", name)
+	}
+	return nil
+}
+
+// isUserCommand reports whether name is an alias or macro.
+func (cfg *config) isUserCommand(name string) bool {
+	name = strings.ToLower(name)
+	_, isAlias := cfg.aliases[name]
+	_, isMacro := cfg.macros[name]
+	return isAlias || isMacro
+}
+
+// saveUserCommands writes the aliases and macros to the config file.
+func (cfg *config) saveUserCommands() error {
+	if cfg.settingsPath == "" {
+		return nil
+	}
+
+	var aliases, macros any
+	if len(cfg.aliases) > 0 {
+		aliases = cfg.aliases
+	}
+	if len(cfg.macros) > 0 {
+		macros = cfg.macros
+	}
+
+	if err := saveSetting(cfg.settingsPath, "aliases", aliases); err != nil {
+		return err
+	}
+	return saveSetting(cfg.settingsPath, "macros", macros)
+}
+
+// expandUserCommand returns the steps an alias or macro runs, each a
+// command line, with any remaining arguments appended to an alias and
+// substituted into the words of a macro's steps. ok is false if name is
+// neither.
+func expandUserCommand(cfg *config, name string, args []string) (steps [][]chainLink, ok bool, err error) {
+	if expansion, isAlias := cfg.aliases[name]; isAlias {
+		words, err := splitArgs(expansion)
+		if err != nil {
+			return nil, true, fmt.Errorf("alias %s: %w", name, err)
+		}
+		return [][]chainLink{{{stages: [][]string{append(words, args...)}}}}, true, nil
+	}
+
+	macro, isMacro := cfg.macros[name]
+	if !isMacro {
+		return nil, false, nil
+	}
+
+	for _, step := range macro {
+		links, err := splitCommandLine(step)
+		if err != nil {
+			return nil, true, fmt.Errorf("macro %s: %w", name, err)
+		}
+
+		for _, link := range links {
+			for i, stage := range link.stages {
+				if link.stages[i], err = substituteArgs(name, stage, args); err != nil {
+					return nil, true, err
+				}
+			}
+		}
+		steps = append(steps, links)
+	}
+
+	return steps, true, nil
+}
+
+// substituteArgs replaces the parameters in the words of one command in a
+// step of the named macro with args.
+func substituteArgs(name string, words []string, args []string) ([]string, error) {
+	var line []string
+	for _, word := range words {
+		if word == "$@" {
+			line = append(line, args...)
+			continue
+		}
+
+		var missing error
+		word = macroParam.ReplaceAllStringFunc(word, func(param string) string {
+			if param == "$@" {
+				return strings.Join(args, " ")
+			}
+			n, _ := strconv.Atoi(param[1:])
+			if n > len(args) {
+				missing = fmt.Errorf("macro %s: missing argument %s", name, param)
+				return ""
+			}
+			return args[n-1]
+		})
+		if missing != nil {
+			return nil, missing
+		}
+		line = append(line, word)
+	}
+
+	return line, nil
+}
+
+// checkUserCommandCycle reports an alias or macro that would end up running
+// itself.
+func checkUserCommandCycle(cfg *config, name string) error {
+	var visit func(name string, path []string) error
+	visit = func(name string, path []string) error {
+		if i := slices.Index(path, name); i >= 0 {
+			return cycleError(append(path[i:], name))
+		}
+		path = append(path, name)
+
+		var commands [][]string
+		if expansion, ok := cfg.aliases[name]; ok {
+			words, _ := splitArgs(expansion)
+			commands = append(commands, words)
+		}
+		for _, step := range cfg.macros[name] {
+			links, _ := splitCommandLine(step)
+			for _, link := range links {
+				commands = append(commands, link.stages[0])
+			}
+		}
+
+		for _, words := range commands {
+			if len(words) == 0 {
+				continue
+			}
+			next := strings.ToLower(words[0])
+			if cfg.isUserCommand(next) {
+				if err := visit(next, slices.Clip(path)); err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	}
+
+	return visit(name, nil)
+}
+
+func cycleError(path []string) error {
+	return fmt.Errorf("%s expands to itself: %s", path[0], strings.Join(path, " -> "))
+}
+
+// quoteArgs joins words into a command line that splitArgs splits back into
+// the same words.
+func quoteArgs(words []string) string {
+	quoted := make([]string, len(words))
+	for i, word := range words {
+		if word != "" && !strings.ContainsAny(word, " \t\n\r\"'\\") {
+			quoted[i] = word
+			continue
+		}
+		quoted[i] = "'" + strings.ReplaceAll(word, "'", `'\''`) + "'"
+	}
+
+	return strings.Join(quoted, " ")
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// loadUserCommands copies the aliases and macros from the config file into
+// cfg, warning about any that would hide a built-in command.
+func loadUserCommands(cfg *config, s settings) {
+	cfg.aliases = make(map[string]string)
+	cfg.macros = make(map[string][]string)
+
+	for name, expansion := range s.Aliases {
+		cfg.aliases[strings.ToLower(name)] = expansion
+	}
+	for name, steps := range s.Macros {
+		cfg.macros[strings.ToLower(name)] = steps
+	}
+
+	for _, name := range append(sortedKeys(cfg.aliases), sortedKeys(cfg.macros)...) {
+		if _, ok := lookupCommand(name); ok {
+			fmt.Fprintf(cfg.errOut, "Warning: ignoring %s from the config file: it is a built-in command\n", name)
+			delete(cfg.aliases, name)
+			delete(cfg.macros, name)
+		}
+	}
+}
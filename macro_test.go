@@ -0,0 +1,208 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAliasesAndMacros(t *testing.T) {
+	cases := []struct {
+		name       string
+		script     string
+		status     int
+		expected   []string
+		unexpected []string
+		errors     []string
+	}{
+		{
+			name:     "alias",
+			script:   "alias fish explore pastoria-city-area\nfish\n",
+			expected: []string{"Exploring pastoria-city-area...\n", " - magikarp\n"},
+		},
+		{
+			name:     "alias arguments are appended",
+			script:   "alias ex explore\nex canalave-city-area\n",
+			expected: []string{"Exploring canalave-city-area...\n"},
+		},
+		{
+			name:     "alias of an alias",
+			script:   "alias ex explore\nalias fish ex pastoria-city-area\nfish\n",
+			expected: []string{"Exploring pastoria-city-area...\n"},
+		},
+		{
+			name:     "macro substitutes arguments",
+			script:   "macro twice \"explore $1\" \"explore $2\"\ntwice pastoria-city-area canalave-city-area\n",
+			expected: []string{"Exploring pastoria-city-area...\n", "Exploring canalave-city-area...\n"},
+		},
+		{
+			name:     "macro with all arguments",
+			script:   "macro go \"explore $@\"\ngo 'pastoria city area'\n",
+			expected: []string{"Exploring pastoria-city-area...\n"},
+		},
+		{
+			name:       "macro missing an argument",
+			script:     "macro twice \"explore $1\" \"explore $2\"\ntwice pastoria-city-area\n",
+			status:     1,
+			unexpected: []string{"Exploring"},
+			errors:     []string{"macro twice: missing argument $2"},
+		},
+		{
+			name:       "macro stops at the first failure",
+			script:     "macro trip \"explore nowhere-area\" \"explore canalave-city-area\"\ntrip\n",
+			status:     1,
+			errors:     []string{"location nowhere-area not found"},
+			unexpected: []string{"Exploring canalave-city-area"},
+		},
+		{
+			name:   "cycles are refused",
+			script: "alias a b\nalias b c\nmacro c \"map\" \"a\"\n",
+			status: 1,
+			errors: []string{"c expands to itself: c -> a -> b -> c"},
+		},
+		{
+			name:   "built-in names are refused",
+			script: "alias map explore canalave-city-area\nalias dex map\n",
+			status: 1,
+			errors: []string{"map is a built-in command", "dex is a built-in command"},
+		},
+		{
+			name:     "alias of a command with flags",
+			script:   "alias s sync --workers 2 move\nalias s\nalias --delete s\n",
+			expected: []string{"s: sync --workers 2 move\n"},
+		},
+		{
+			name:     "list and delete",
+			script:   "alias fish explore 'pastoria city area'\nmacro m map\nalias\nmacro\nalias --delete fish\nalias fish\n",
+			status:   1,
+			expected: []string{"fish: explore 'pastoria city area'\n", "m: map\n"},
+			errors:   []string{"no alias named fish"},
+		},
+		{
+			name:   "delete after the name",
+			script: "alias fish explore pastoria-city-area\nmacro m map\nalias fish --delete\nmacro m --delete\nalias fish\nmacro m\n",
+			status: 1,
+			errors: []string{"no alias named fish", "no macro named m"},
+		},
+		{
+			name:       "macro step with a pipeline",
+			script:     "macro tentacool \"explore $1 | grep tentacool\"\ntentacool pastoria-city-area\n",
+			expected:   []string{" - tentacool\n"},
+			unexpected: []string{" - magikarp\n"},
+		},
+		{
+			name:     "macro step that carries on past a failure",
+			script:   "macro trip \"explore nowhere-area; explore canalave-city-area\"\ntrip\n",
+			status:   1,
+			expected: []string{"Exploring canalave-city-area...\n"},
+			errors:   []string{"location nowhere-area not found"},
+		},
+		{
+			name:       "macro step that stops at a failure",
+			script:     "macro trip \"explore nowhere-area && explore canalave-city-area\" map\ntrip\n",
+			status:     1,
+			unexpected: []string{"Exploring canalave-city-area", "canalave-city-area\n"},
+			errors:     []string{"location nowhere-area not found"},
+		},
+		{
+			name:   "names with command line syntax are refused",
+			script: "alias 'a|b' map\nalias 'a;b' map\nmacro 'a&b' map\nmacro '#a' map\n",
+			status: 1,
+			errors: []string{`"a|b" is not a valid name`, `"a;b" is not a valid name`, `"a&b" is not a valid name`, `"#a" is not a valid name`},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cfg := newTestConfig(t)
+
+			status := runScript(cfg, strings.NewReader(c.script))
+			output := takeOutput(cfg)
+			errors := takeStderr(cfg)
+
+			if status != c.status {
+				t.Errorf("expected status %d, got %d", c.status, status)
+			}
+			for _, expected := range c.expected {
+				if !strings.Contains(output, expected) {
+					t.Errorf("expected output to contain %q, got:\n%s", expected, output)
+				}
+			}
+			for _, unexpected := range c.unexpected {
+				if strings.Contains(output, unexpected) {
+					t.Errorf("expected output not to contain %q, got:\n%s", unexpected, output)
+				}
+			}
+			for _, expected := range c.errors {
+				if !strings.Contains(errors, "Error: "+expected+"\n") {
+					t.Errorf("expected errors to contain %q, got:\n%s", expected, errors)
+				}
+			}
+		})
+	}
+}
+
+func TestUserCommandCycleAtRunTime(t *testing.T) {
+	cfg := newTestConfig(t)
+	// A hand-edited config file is not checked when it is loaded.
+	cfg.aliases["a"] = "b"
+	cfg.macros["b"] = []string{"map", "a"}
+
+	err := runCommand(cfg, []string{"a"})
+	if err == nil || err.Error() != "a expands to itself: a -> b -> a" {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestUserCommandsArePersisted(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"backend": "graphql"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := newTestConfig(t)
+	cfg.settingsPath = path
+	for _, line := range []string{"alias fish explore 'pastoria city area'", `macro twice "explore $1" "explore $2"`} {
+		if err := runLine(cfg, line, failOnReport(t)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	s, err := loadSettings(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Backend != "graphql" {
+		t.Errorf("expected the rest of the file to be kept, got backend %s", s.Backend)
+	}
+	if s.Aliases["fish"] != "explore 'pastoria city area'" {
+		t.Errorf("unexpected aliases: %v", s.Aliases)
+	}
+	if !reflect.DeepEqual(s.Macros["twice"], []string{"explore $1", "explore $2"}) {
+		t.Errorf("unexpected macros: %v", s.Macros)
+	}
+
+	loaded := newTestConfig(t)
+	loadUserCommands(loaded, s)
+	if !reflect.DeepEqual(loaded.aliases, cfg.aliases) || !reflect.DeepEqual(loaded.macros, cfg.macros) {
+		t.Errorf("expected loaded commands to match, got %v and %v", loaded.aliases, loaded.macros)
+	}
+}
+
+func TestQuoteArgs(t *testing.T) {
+	for _, words := range [][]string{
+		{"explore", "pastoria-city-area"},
+		{"catch", "mr mime"},
+		{"say", "it's", `a "quote"`, `back\slash`, ""},
+	} {
+		actual, err := splitArgs(quoteArgs(words))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(actual, words) {
+			t.Errorf("expected %q to round trip, got %q", words, actual)
+		}
+	}
+}
@@ -1,244 +1,495 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"math/rand"
+	"net/http"
 	"os"
-	"os/signal"
+	"path/filepath"
+	"pokedexcli/internal/lineedit"
 	"pokedexcli/internal/pokeapi"
+	"pokedexcli/internal/pokecache"
+	"pokedexcli/internal/render"
 	"strings"
-	"syscall"
+	"text/template"
+	"time"
 
 	"github.com/eiannone/keyboard"
+	"golang.org/x/term"
 )
 
 type cliCommand struct {
 	name        string
 	description string
-	callback    func(*config) error
+	// usage names the positional arguments, such as "<location>".
+	usage string
+	// minArgs and maxArgs bound the number of positional arguments; a
+	// negative maxArgs means there is no upper bound.
+	minArgs int
+	maxArgs int
+	// flags, if set, declares the flags the command accepts.
+	flags func(*flag.FlagSet)
+	// flagsFirst stops reading flags at the first positional argument, for
+	// commands whose later arguments are command lines with flags of their
+	// own.
+	flagsFirst bool
+	// group is the heading the command is listed under in help.
+	group string
+	// aliases are other names the command can be run by.
+	aliases []string
+	// examples are sample command lines shown by "help <command>".
+	examples []string
+	// callback runs the command. Whatever it has to show is returned as a
+	// result, or nil, so pipelines can filter it before it is printed.
+	callback func(*config, commandArgs) (result, error)
 }
 
 type config struct {
-	next     string
-	previous string
-	name     string
+	pokeapiClient pokeapi.Backend
+	next          string
+	previous      string
+
+	// profile is the trainer profile in use. Its settings choose the
+	// prompt, the history and where it is saved, or "" to not save it.
+	profile     string
+	prompt      *template.Template
+	history     *lineedit.History
+	historyPath string
+
+	// settingsPath is the config file aliases and macros are saved to, or
+	// "" to not save them. savePath is the file caught Pokemon are saved to
+	// whenever they change, or "" to not save them.
+	settingsPath string
+	savePath     string
+	aliases      map[string]string
+	macros       map[string][]string
+
+	// out is where commands write what they print and errOut where errors
+	// and warnings go, rand decides whether a catch succeeds and now tells
+	// the time. Tests replace all four.
+	out    io.Writer
+	errOut io.Writer
+	rand   *rand.Rand
+	now    func() time.Time
+
+	// output is the format map, explore, inspect and pokedex print in.
+	output render.Format
+
+	// confirm asks the user a yes or no question. It is nil when there is
+	// no one to ask, such as when running a script.
+	confirm func(question string) bool
+	// page shows a command's output, in a pager if it is too long for the
+	// screen. It is nil when output is not to a terminal; paging turns it
+	// off for the session.
+	page   func(output string)
+	paging bool
+
+	// location is the last location explored and lead the first Pokemon
+	// caught, for the prompt.
+	location string
+	lead     string
+
+	// recorder writes command lines and their output to a transcript while
+	// recording, and is nil otherwise. replaying is set while replay runs a
+	// transcript.
+	recorder  *transcriptRecorder
+	replaying bool
+
+	// locations and encounters are what the last map and explore showed,
+	// for Tab completion.
+	locations  []string
+	encounters []string
 }
 
 type Pokemon struct {
-	name   string
-	height int
-	weight int
-	types  []string
-	stats  map[string]int
+	name     string
+	height   int
+	weight   int
+	types    []string
+	stats    map[string]int
+	caughtAt time.Time
 }
 
-const locationAreaBaseURL = "https://pokeapi.co/api/v2/location-area/"
-const pokemonBaseURL = "https://pokeapi.co/api/v2/pokemon/"
+const cacheInterval = 5 * time.Minute
+const syncWorkers = 8
+
+// profileTimeout bounds all the requests inspect makes for a Pokemon's
+// species and evolutions together.
+var profileTimeout = 10 * time.Second
 
 var commands map[string]cliCommand
 var pokedex map[string]Pokemon
 
 func main() {
-	commands = make(map[string]cliCommand)
+	commands = newCommands()
+
+	configPath := flag.String("config", "", "path to the config file (default config.json in the profile's directory)")
+	profile := flag.String("profile", "", "trainer profile to use (default the last one switched to)")
+	backend := flag.String("backend", "", "API backend to use: rest or graphql (overrides the config file)")
+	record := flag.String("record", "", "record API responses to this directory")
+	replay := flag.String("replay", "", "answer API requests from responses recorded in this directory")
+	commandLine := flag.String("c", "", "run commands separated by ';' or '&&' and exit")
+	script := flag.String("script", "", "run commands from a script file, one per line, and exit")
+	save := flag.String("save", "", "file to save caught Pokemon to (overrides the config file)")
+	output := flag.String("output", "text", "output format for map, explore, inspect and pokedex: text, json, yaml, csv or table")
+	flag.Usage = usage
+	// The flag package prints the error and usage; the status is up to us.
+	flag.CommandLine.Init(os.Args[0], flag.ContinueOnError)
+
+	args, err := parseArgs(flag.CommandLine, os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(0)
+	}
+	if err != nil {
+		os.Exit(2)
+	}
+
+	if *profile == "" {
+		*profile = activeProfile()
+	} else if *profile = strings.ToLower(*profile); !profileExists(*profile) {
+		fmt.Printf("no profile named %s\n", *profile)
+		os.Exit(2)
+	}
+
+	settings, settingsPath, err := loadProfileSettings(*profile, *configPath)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	if *backend != "" {
+		settings.Backend = *backend
+	}
+	if *save != "" {
+		settings.SaveFile = *save
+	}
+
+	format, err := render.ParseFormat(*output)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(2)
+	}
+
+	pokeapiClient, err := newPokeapiClient(settings, *record, *replay)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
 	config := &config{
-		next:     "",
-		previous: "",
+		pokeapiClient: pokeapiClient,
+		profile:       *profile,
+		history:       lineedit.NewHistory(settings.HistorySize, settings.HistoryDedupe),
+		next:          "",
+		previous:      "",
+		out:           os.Stdout,
+		errOut:        os.Stderr,
+		rand:          rand.New(rand.NewSource(time.Now().UnixNano())),
+		now:           time.Now,
+		output:        format,
+		paging:        true,
+	}
+	warn := func(err error) {
+		fmt.Printf("Error: %s\n", err)
+	}
+	if err := config.useSettings(settings, settingsPath, warn); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	switch {
+	case len(args) > 0:
+		os.Exit(runOneShot(config, args))
+	case *commandLine != "":
+		os.Exit(runScript(config, strings.NewReader(*commandLine)))
+	case *script != "":
+		file, err := os.Open(*script)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		status := runScript(config, file)
+		file.Close()
+		os.Exit(status)
+	case !term.IsTerminal(int(os.Stdin.Fd())):
+		os.Exit(runScript(config, os.Stdin))
+	}
+
+	if err := runRepl(config); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+}
+
+// runRepl reads commands from the terminal until the user exits.
+func runRepl(config *config) error {
+	// Closing the keyboard takes the terminal out of raw mode. Every way
+	// out of the REPL returns through here, including signals, which end
+	// the input rather than the program.
+	if err := keyboard.Open(); err != nil {
+		return err
 	}
-	pokedex = make(map[string]Pokemon)
+	defer keyboard.Close()
+
+	events, err := openTerminalEvents()
+	if err != nil {
+		return err
+	}
+	defer events.close()
+
+	tty := os.Stdout
+	config.confirm = func(question string) bool {
+		return confirm(tty, question)
+	}
+	if term.IsTerminal(int(tty.Fd())) {
+		config.page = func(output string) {
+			pageOutput(output, events.next)
+		}
+	}
+
+	editor := lineedit.New(tty, defaultPrompt)
+	editor.SetCompleter(completer(config))
+	editor.SetHistory(config.history)
+	input := &keyInput{editor: editor, nextKey: events.next, width: terminalWidth}
+
+	return newRepl(config, input).Run()
+}
+
+// newCommands returns the registry of every REPL command.
+func newCommands() map[string]cliCommand {
+	commands := make(map[string]cliCommand)
 
 	commands["help"] = cliCommand{
 		name:        "help",
-		description: "Displays a help message",
+		description: "Displays a help message, or details about one command",
+		usage:       "[command]",
+		maxArgs:     1,
+		group:       groupGeneral,
+		aliases:     []string{"?"},
+		examples:    []string{"help", "help explore"},
 		callback:    commandHelp,
 	}
 
 	commands["exit"] = cliCommand{
 		name:        "exit",
 		description: "Exit the Pokedex",
+		group:       groupGeneral,
+		aliases:     []string{"q", "quit"},
 		callback:    commandExit,
 	}
 
 	commands["map"] = cliCommand{
 		name:        "map",
 		description: "Display 20 map locations",
+		group:       groupExploring,
 		callback:    commandMap,
 	}
 
 	commands["mapb"] = cliCommand{
 		name:        "mapb",
 		description: "Display the previous 20 map locations if they exist",
+		group:       groupExploring,
 		callback:    commandMapb,
 	}
 
 	commands["explore"] = cliCommand{
 		name:        "explore",
 		description: "See all Pokemon in a location",
+		usage:       "<location>",
+		minArgs:     1,
+		maxArgs:     1,
+		group:       groupExploring,
+		examples:    []string{"explore pastoria-city-area", `explore "eterna forest area"`},
 		callback:    commandExplore,
 	}
 
 	commands["catch"] = cliCommand{
 		name:        "catch",
 		description: "Catch a Pokemon",
+		usage:       "<pokemon>",
+		minArgs:     1,
+		maxArgs:     1,
+		group:       groupPokemon,
+		examples:    []string{"catch pikachu", `catch "mr mime"`},
 		callback:    commandCatch,
 	}
 
 	commands["inspect"] = cliCommand{
 		name:        "inspect",
 		description: "Inspect a captured Pokemon",
+		usage:       "<pokemon>",
+		minArgs:     1,
+		maxArgs:     1,
+		group:       groupPokemon,
+		examples:    []string{"inspect pikachu"},
 		callback:    commandInspect,
 	}
 
 	commands["pokedex"] = cliCommand{
 		name:        "pokedex",
 		description: "Display all caught Pokemon",
+		group:       groupPokemon,
+		aliases:     []string{"dex"},
 		callback:    commandPokedex,
 	}
 
-	if err := keyboard.Open(); err != nil {
-		fmt.Println(err)
-		os.Exit(1)
+	commands["save"] = cliCommand{
+		name:        "save",
+		description: "Save your caught Pokemon; they are also saved whenever you catch one",
+		usage:       "[file]",
+		maxArgs:     1,
+		group:       groupPokemon,
+		examples:    []string{"save", "save backup.json"},
+		callback:    commandSave,
 	}
-	defer keyboard.Close()
 
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
-	go func() {
-		<-sigChan
-		keyboard.Close()
-		os.Exit(0)
-	}()
-
-	var input string
-	var enterPressed bool
-	var previousCommands []string
-	var historyIndex int
-
-	for {
-		enterPressed = false
-		fmt.Print("Pokedex > ")
-
-		for {
-			char, key, err := keyboard.GetKey()
-			if err != nil {
-				fmt.Println(err)
-				continue
-			}
-
-			if key == keyboard.KeyEnter {
-				enterPressed = true
-				fmt.Println() // Move to the next line
-				break
-			} else if key == keyboard.KeyBackspace || key == keyboard.KeyBackspace2 {
-				if len(input) > 0 {
-					input = input[:len(input)-1]
-					fmt.Print("\rPokedex > " + input + " \b")
-				}
-			} else if key == keyboard.KeyArrowUp {
-				if historyIndex > 0 {
-					historyIndex--
-					input = previousCommands[historyIndex]
-					fmt.Print("\rPokedex > " + input + strings.Repeat(" ", 50) + "\rPokedex > " + input)
-				}
-			} else if key == keyboard.KeyArrowDown {
-				if historyIndex < len(previousCommands)-1 {
-					historyIndex++
-					input = previousCommands[historyIndex]
-					fmt.Print("\rPokedex > " + input + strings.Repeat(" ", 50) + "\rPokedex > " + input)
-				} else {
-					historyIndex = len(previousCommands)
-					input = ""
-					fmt.Print("\rPokedex > " + strings.Repeat(" ", 50) + "\rPokedex > ")
-				}
-			} else if key == keyboard.KeySpace {
-				input += " "
-				fmt.Print(" ")
-			} else {
-				input += string(char)
-				fmt.Print(string(char)) // Echo the character
-			}
-		}
+	commands["load"] = cliCommand{
+		name:        "load",
+		description: "Replace your caught Pokemon with those in a save file",
+		usage:       "[file]",
+		maxArgs:     1,
+		group:       groupPokemon,
+		examples:    []string{"load", "load backup.json"},
+		callback:    commandLoad,
+	}
 
-		if enterPressed {
-			inputArray := cleanInput(input)
-			if len(inputArray) != 0 {
-				command, ok := commands[inputArray[0]]
-				if !ok {
-					fmt.Println("Unknown command")
-					input = ""
-					continue
-				}
-
-				previousCommands = append(previousCommands, strings.Join(inputArray, " "))
-				historyIndex = len(previousCommands)
-
-				if len(inputArray) > 1 && (inputArray[0] == "explore" || inputArray[0] == "catch" || inputArray[0] == "inspect") {
-					config.name = inputArray[1]
-				}
-
-				err := command.callback(config)
-				if err != nil {
-					fmt.Printf("Error: %s\n", err)
-				}
-			}
-			input = ""
-		}
+	commands["history"] = cliCommand{
+		name:        "history",
+		description: "List previous commands; run one again with !<number>",
+		group:       groupGeneral,
+		examples:    []string{"history", "!3", "!!"},
+		callback:    commandHistory,
 	}
-}
 
-func cleanInput(text string) []string {
-	parts := strings.Fields(strings.ToLower(strings.Trim(text, " ")))
-	return parts
-}
+	commands["alias"] = cliCommand{
+		name:        "alias",
+		description: "List, show, define or delete shortcuts for a command",
+		usage:       "[name [command...]]",
+		maxArgs:     -1,
+		flags: func(flags *flag.FlagSet) {
+			flags.Bool("delete", false, "delete the named alias")
+		},
+		flagsFirst: true,
+		group:      groupGeneral,
+		examples:   []string{"alias gym explore pewter-city-area", "alias gym", "alias --delete gym"},
+		callback:   commandAlias,
+	}
 
-func commandExit(cfg *config) error {
-	fmt.Println("Closing the Pokedex... Goodbye!")
-	keyboard.Close()
-	os.Exit(0)
-	return nil
-}
+	commands["macro"] = cliCommand{
+		name:        "macro",
+		description: "List, show, define or delete commands that run several steps",
+		usage:       "[name [step...]]",
+		maxArgs:     -1,
+		flags: func(flags *flag.FlagSet) {
+			flags.Bool("delete", false, "delete the named macro")
+		},
+		flagsFirst: true,
+		group:      groupGeneral,
+		examples:   []string{`macro fish "explore $1" "catch magikarp"`, "fish pastoria-city-area", "macro --delete fish"},
+		callback:   commandMacro,
+	}
 
-func commandHelp(cfg *config) error {
-	fmt.Println("Welcome to the Pokedex!")
-	fmt.Println("Usage:")
-	fmt.Println("")
+	commands["set"] = cliCommand{
+		name:        "set",
+		description: "Show or change a setting for this session, such as the output format",
+		usage:       "[name [value]]",
+		maxArgs:     2,
+		group:       groupGeneral,
+		examples:    []string{"set", "set output json"},
+		callback:    commandSet,
+	}
 
-	for _, command := range commands {
-		fmt.Printf("%s: %s\n", command.name, command.description)
+	commands["profile"] = cliCommand{
+		name:        "profile",
+		description: "List, create, switch or delete trainer profiles, each with its own Pokemon, history and settings",
+		usage:       "[list | new <name> | switch <name> | delete <name>]",
+		maxArgs:     2,
+		group:       groupGeneral,
+		examples:    []string{"profile", "profile new ash", "profile switch misty", "profile delete ash"},
+		callback:    commandProfile,
 	}
 
-	return nil
-}
+	commands["record"] = cliCommand{
+		name:        "record",
+		description: "Record the commands you run and their output to a transcript file",
+		usage:       "[start <file> | stop]",
+		maxArgs:     2,
+		group:       groupGeneral,
+		examples:    []string{"record start session.txt", "record", "record stop"},
+		callback:    commandRecord,
+	}
 
-func commandMap(cfg *config) error {
-	if len(cfg.next) == 0 {
-		cfg.next = locationAreaBaseURL
+	commands["replay"] = cliCommand{
+		name:        "replay",
+		description: "Run a transcript's commands again and show where their output differs",
+		usage:       "<file>",
+		minArgs:     1,
+		maxArgs:     1,
+		group:       groupGeneral,
+		examples:    []string{"replay session.txt"},
+		callback:    commandReplay,
 	}
 
-	locations := pokeapi.GetLocations(cfg.next)
+	commands["sync"] = cliCommand{
+		name:        "sync",
+		description: "Download location areas, Pokemon, species, types and moves for offline use",
+		usage:       "[resource...]",
+		maxArgs:     -1,
+		flags: func(flags *flag.FlagSet) {
+			flags.Int("workers", syncWorkers, "number of parallel downloads")
+		},
+		group:    groupOffline,
+		examples: []string{"sync", "sync pokemon pokemon-species", "sync --workers 2 move"},
+		callback: commandSync,
+	}
 
-	cfg.next = locations.Next
+	return commands
+}
 
-	if locations.Previous != nil {
-		cfg.previous = *locations.Previous
-	} else {
-		cfg.previous = ""
+func cleanInput(text string) []string {
+	parts := strings.Fields(strings.ToLower(strings.Trim(text, " ")))
+	return parts
+}
+
+func commandExit(cfg *config, args commandArgs) (result, error) {
+	fmt.Fprintln(cfg.out, "Closing the Pokedex... Goodbye!")
+	if err := cfg.stopRecording(); err != nil {
+		return nil, err
 	}
+	return nil, errExit
+}
 
-	for _, location := range locations.Results {
-		fmt.Println(location.Name)
+func commandMap(cfg *config, args commandArgs) (result, error) {
+	locations, err := cfg.pokeapiClient.GetLocations(cfg.next)
+	if err != nil {
+		return nil, err
 	}
 
-	return nil
+	return showLocations(cfg, locations), nil
 }
 
-func commandMapb(cfg *config) error {
+func commandMapb(cfg *config, args commandArgs) (result, error) {
 	if len(cfg.previous) == 0 {
-		fmt.Println("you're on the first page")
-		return nil
+		if cfg.structured() {
+			return newLocationResult(nil), nil
+		}
+		return textResult{"you're on the first page"}, nil
 	}
 
-	locations := pokeapi.GetLocations(cfg.previous)
+	locations, err := cfg.pokeapiClient.GetLocations(cfg.previous)
+	if err != nil {
+		return nil, err
+	}
+
+	return showLocations(cfg, locations), nil
+}
 
+// showLocations returns a page of locations and remembers the pages either
+// side of it for map and mapb.
+func showLocations(cfg *config, locations pokeapi.Locations) result {
 	cfg.next = locations.Next
 
 	if locations.Previous != nil {
@@ -247,58 +498,83 @@ func commandMapb(cfg *config) error {
 		cfg.previous = ""
 	}
 
+	cfg.locations = nil
+	records := make([]locationRecord, 0, len(locations.Results))
 	for _, location := range locations.Results {
-		fmt.Println(location.Name)
+		cfg.locations = append(cfg.locations, location.Name)
+		records = append(records, locationRecord{Name: location.Name, URL: location.URL})
 	}
 
-	return nil
+	return newLocationResult(records)
 }
 
-func commandExplore(cfg *config) error {
-	if len(cfg.name) == 0 {
-		fmt.Println("Please enter a location name")
-		return nil
+func commandExplore(cfg *config, args commandArgs) (result, error) {
+	name := resourceName(args.arg(0))
+	if name == "" {
+		return nil, errors.New("please enter a location name")
 	}
 
-	fmt.Printf("Exploring %s...\n", cfg.name)
-
-	locationDetails := pokeapi.GetLocationDetails(locationAreaBaseURL + cfg.name)
-
-	if locationDetails.PokemonEncounters == nil {
-		fmt.Println("No Pokemon found in this location")
-		return nil
+	notFound := fmt.Errorf("location %s not found", name)
+	name, err := checkResourceName(cfg, "location-area", name, notFound)
+	if err != nil {
+		return nil, err
 	}
 
-	fmt.Println("Found Pokemon:")
+	locationDetails, err := cfg.pokeapiClient.GetLocationDetails(name)
+	if errors.Is(err, pokeapi.ErrNotFound) {
+		return nil, notFound
+	}
+	if err != nil {
+		return nil, err
+	}
 
+	cfg.location = name
+	cfg.encounters = nil
+	records := make([]encounterRecord, 0, len(locationDetails.PokemonEncounters))
 	for _, encounter := range locationDetails.PokemonEncounters {
-		fmt.Println(" - " + encounter.Pokemon.Name)
+		cfg.encounters = append(cfg.encounters, encounter.Pokemon.Name)
+		records = append(records, encounterRecord{Location: name, Pokemon: encounter.Pokemon.Name})
 	}
 
-	return nil
+	return newEncounterResult(name, records), nil
 }
 
-func commandCatch(cfg *config) error {
-	if len(cfg.name) == 0 {
-		fmt.Println("Please enter a Pokemon name")
-		return nil
+func commandCatch(cfg *config, args commandArgs) (result, error) {
+	name := resourceName(args.arg(0))
+	if name == "" {
+		return nil, errors.New("please enter a Pokemon name")
 	}
 
-	fmt.Printf("Throwing a Pokeball at %s...\n", cfg.name)
-
-	pokemonDetails := pokeapi.GetPokemonDetails(pokemonBaseURL + cfg.name)
+	notFound := fmt.Errorf("Pokemon %s not found", name)
+	name, err := checkResourceName(cfg, "pokemon", name, notFound)
+	if err != nil {
+		return nil, err
+	}
 
-	if pokemonDetails.Name == "" {
-		fmt.Println("Pokemon not found")
-		return nil
+	pokemonDetails, err := cfg.pokeapiClient.GetPokemonSummary(name)
+	if errors.Is(err, pokeapi.ErrNotFound) {
+		return nil, notFound
+	}
+	if err != nil {
+		return nil, err
 	}
 
 	difficutlyChance := int(float64(pokemonDetails.BaseExperience) * 0.5)
 
-	chance := rand.Intn(pokemonDetails.BaseExperience)
+	chance := cfg.rand.Intn(pokemonDetails.BaseExperience)
+
+	// The throw is only narrated in text mode, so it stays out of structured
+	// output; either way the outcome is the result, so it can be piped.
+	var res textResult
+	if !cfg.structured() {
+		res = append(res, fmt.Sprintf("Throwing a Pokeball at %s...", name))
+	}
 
 	if chance > difficutlyChance {
-		fmt.Printf("%s was caught!\n", pokemonDetails.Name)
+		res = append(res, colors.Paint("pokemon", pokemonDetails.Name)+" was caught!")
+		if cfg.lead == "" {
+			cfg.lead = pokemonDetails.Name
+		}
 
 		var types []string
 		for _, t := range pokemonDetails.Types {
@@ -312,51 +588,166 @@ func commandCatch(cfg *config) error {
 		}
 
 		pokedex[pokemonDetails.Name] = Pokemon{
-			name:   pokemonDetails.Name,
-			height: pokemonDetails.Height,
-			weight: pokemonDetails.Weight,
-			types:  types,
-			stats:  stats,
+			name:     pokemonDetails.Name,
+			height:   pokemonDetails.Height,
+			weight:   pokemonDetails.Weight,
+			types:    types,
+			stats:    stats,
+			caughtAt: cfg.now(),
 		}
-	} else {
-		fmt.Printf("%s escaped!\n", pokemonDetails.Name)
+		if err := cfg.autosave(); err != nil {
+			return nil, fmt.Errorf("%s was caught but not saved: %w", pokemonDetails.Name, err)
+		}
+		return res, nil
 	}
 
-	return nil
+	return append(res, pokemonDetails.Name+" escaped!"), nil
 }
 
-func commandInspect(cfg *config) error {
-	if len(cfg.name) == 0 {
-		fmt.Println("Please enter a Pokemon name")
-		return nil
+func commandInspect(cfg *config, args commandArgs) (result, error) {
+	name := resourceName(args.arg(0))
+	if name == "" {
+		return nil, errors.New("please enter a Pokemon name")
 	}
 
-	pokemon, ok := pokedex[cfg.name]
-	if !ok {
-		fmt.Println("you have not caught that pokemon")
-		return nil
+	caught := make([]string, 0, len(pokedex))
+	for caughtName := range pokedex {
+		caught = append(caught, caughtName)
+	}
+	name, err := checkName(cfg, name, caught, fmt.Errorf("you have not caught %s", name))
+	if err != nil {
+		return nil, err
+	}
+	pokemon := pokedex[name]
+
+	// Everything but the species and evolutions is in the Pokedex, so those
+	// are left out rather than failing when the API cannot be reached or no
+	// longer knows the Pokemon. Any other error is a real failure.
+	ctx, cancel := context.WithTimeout(context.Background(), profileTimeout)
+	defer cancel()
+	profile, err := cfg.pokeapiClient.GetPokemonProfile(ctx, pokemon.name)
+	if err != nil {
+		if !errors.Is(err, pokeapi.ErrNotFound) && !pokeapi.IsUnreachable(err) {
+			return nil, err
+		}
+		fmt.Fprintf(cfg.errOut, "Warning: leaving out the species and evolutions of %s: %v\n", pokemon.name, err)
 	}
 
-	fmt.Printf("Name: %s\n", pokemon.name)
-	fmt.Printf("Height: %d\n", pokemon.height)
-	fmt.Printf("Weight: %d\n", pokemon.weight)
-	fmt.Printf("Stats:\n")
-	for k, v := range pokemon.stats {
-		fmt.Printf(" -%s: %d\n", k, v)
+	return newPokemonResult(pokemonRecord{
+		Name:       pokemon.name,
+		Height:     pokemon.height,
+		Weight:     pokemon.weight,
+		Stats:      pokemon.stats,
+		Types:      pokemon.types,
+		Species:    profile.Species,
+		Genus:      profile.Genus,
+		Generation: profile.Generation,
+		FlavorText: profile.FlavorText,
+		Evolutions: profile.Evolutions,
+	}), nil
+}
+
+func commandPokedex(cfg *config, args commandArgs) (result, error) {
+	records := make([]pokedexRecord, 0, len(pokedex))
+	for _, name := range sortedKeys(pokedex) {
+		pokemon := pokedex[name]
+		records = append(records, pokedexRecord{
+			Name:   pokemon.name,
+			Height: pokemon.height,
+			Weight: pokemon.weight,
+			Types:  pokemon.types,
+		})
 	}
-	fmt.Printf("Types:\n")
-	for _, t := range pokemon.types {
-		fmt.Printf(" - %s\n", t)
+
+	return newPokedexResult(records), nil
+}
+
+func commandSync(cfg *config, args commandArgs) (result, error) {
+	workers := args.intFlag("workers")
+	if workers < 1 {
+		return nil, errors.New("--workers must be at least 1")
 	}
 
-	return nil
+	return nil, syncResources(cfg.out, cfg.pokeapiClient, args.positional, workers)
 }
 
-func commandPokedex(cfg *config) error {
-	fmt.Println("Your Pokedex:")
-	for _, pokemon := range pokedex {
-		fmt.Printf(" - %s\n", pokemon.name)
+func syncResources(out io.Writer, client pokeapi.Backend, resources []string, workers int) error {
+	if len(resources) == 0 {
+		resources = pokeapi.SyncResources
 	}
 
+	fmt.Fprintln(out, "Syncing "+strings.Join(resources, ", ")+"...")
+	err := client.Sync(resources, workers, func(progress pokeapi.SyncProgress) {
+		printSyncProgress(out, progress)
+	})
+	if err != nil {
+		fmt.Fprintln(out)
+		return err
+	}
+	fmt.Fprintln(out, "Sync complete")
+
 	return nil
 }
+
+func printSyncProgress(out io.Writer, progress pokeapi.SyncProgress) {
+	const width = 30
+	filled := width * progress.Done / progress.Total
+
+	fmt.Fprintf(out, "\r%-16s [%s%s] %d/%d", progress.Resource, strings.Repeat("#", filled), strings.Repeat("-", width-filled), progress.Done, progress.Total)
+	if progress.Done == progress.Total {
+		fmt.Fprintln(out)
+	}
+}
+
+// newPokeapiClient returns the backend used by commands. When recording or
+// replaying, the disk cache is skipped so every request goes through the
+// cassette directory.
+func newPokeapiClient(settings settings, record, replay string) (pokeapi.Backend, error) {
+	if record != "" && replay != "" {
+		return nil, errors.New("--record and --replay cannot be used together")
+	}
+
+	var client *pokeapi.Client
+	if record == "" && replay == "" {
+		client = pokeapi.NewClient(cacheInterval, openDiskCache())
+	} else {
+		client = pokeapi.NewClient(cacheInterval, nil)
+
+		var transport http.RoundTripper
+		var err error
+		if record != "" {
+			transport, err = pokeapi.NewRecordingTransport(record, nil)
+		} else {
+			transport, err = pokeapi.NewReplayTransport(replay)
+		}
+		if err != nil {
+			return nil, err
+		}
+		client.SetTransport(transport)
+	}
+
+	switch settings.Backend {
+	case "rest":
+		return client, nil
+	case "graphql":
+		return pokeapi.NewGraphQLClient(client, settings.GraphQLURL), nil
+	default:
+		return nil, fmt.Errorf("unknown backend %q: expected rest or graphql", settings.Backend)
+	}
+}
+
+// openDiskCache returns the on-disk response cache in the user's cache
+// directory, or nil if it cannot be created.
+func openDiskCache() *pokecache.DiskCache {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return nil
+	}
+
+	disk, err := pokecache.NewDiskCache(filepath.Join(dir, "pokedexcli"))
+	if err != nil {
+		return nil
+	}
+
+	return disk
+}
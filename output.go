@@ -0,0 +1,349 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"pokedexcli/internal/render"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// A result is what a command returns to be shown: a list of records that
+// pipeline filters can narrow down before it is printed as text or in the
+// configured output format.
+type result interface {
+	render.Tabular
+	// Len returns the number of records.
+	Len() int
+	// field returns the value of the named field of record i.
+	field(i int, name string) (any, bool)
+	// subset returns a result holding the records at indexes, in order.
+	subset(indexes []int) result
+	// writeText writes the result the way the command prints it as text.
+	writeText(w io.Writer)
+}
+
+// recordList is a result made of records of one type. JSON and YAML encode
+// the records themselves, so their field tags define the schema.
+type recordList[T any] struct {
+	records []T
+	// single lists are encoded as their one record rather than a list.
+	single bool
+	header []string
+	row    func(T) []string
+	text   func(w io.Writer, records []T)
+}
+
+func (l *recordList[T]) Header() []string {
+	return l.header
+}
+
+func (l *recordList[T]) Rows() [][]string {
+	rows := make([][]string, 0, len(l.records))
+	for _, record := range l.records {
+		rows = append(rows, l.row(record))
+	}
+	return rows
+}
+
+func (l *recordList[T]) Len() int {
+	return len(l.records)
+}
+
+func (l *recordList[T]) field(i int, name string) (any, bool) {
+	return recordField(l.records[i], name)
+}
+
+func (l *recordList[T]) subset(indexes []int) result {
+	subset := *l
+	subset.records = make([]T, 0, len(indexes))
+	for _, i := range indexes {
+		subset.records = append(subset.records, l.records[i])
+	}
+	return &subset
+}
+
+func (l *recordList[T]) writeText(w io.Writer) {
+	l.text(w, l.records)
+}
+
+func (l *recordList[T]) encoded() any {
+	if l.single && len(l.records) == 1 {
+		return l.records[0]
+	}
+	if l.records == nil {
+		return []T{}
+	}
+	return l.records
+}
+
+func (l *recordList[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(l.encoded())
+}
+
+func (l *recordList[T]) MarshalYAML() (any, error) {
+	return l.encoded(), nil
+}
+
+// recordField returns the field of a record struct whose json tag is name.
+func recordField(record any, name string) (any, bool) {
+	v := reflect.ValueOf(record)
+	for i := 0; i < v.NumField(); i++ {
+		tag, _, _ := strings.Cut(v.Type().Field(i).Tag.Get("json"), ",")
+		if tag == name {
+			return v.Field(i).Interface(), true
+		}
+	}
+	return nil, false
+}
+
+// textResult is the output of commands that print text rather than
+// records, one element per line. Filters treat each line as a record with
+// a single field, "line".
+type textResult []string
+
+// newTextResult splits text into a textResult.
+func newTextResult(text string) textResult {
+	text = strings.TrimSuffix(text, "\n")
+	if text == "" {
+		return textResult{}
+	}
+	return strings.Split(text, "\n")
+}
+
+func (r textResult) Header() []string {
+	return []string{"line"}
+}
+
+func (r textResult) Rows() [][]string {
+	rows := make([][]string, 0, len(r))
+	for _, line := range r {
+		rows = append(rows, []string{line})
+	}
+	return rows
+}
+
+func (r textResult) Len() int {
+	return len(r)
+}
+
+func (r textResult) field(i int, name string) (any, bool) {
+	if name != "line" {
+		return nil, false
+	}
+	return r[i], true
+}
+
+func (r textResult) subset(indexes []int) result {
+	subset := make(textResult, 0, len(indexes))
+	for _, i := range indexes {
+		subset = append(subset, r[i])
+	}
+	return subset
+}
+
+func (r textResult) writeText(w io.Writer) {
+	for _, line := range r {
+		fmt.Fprintln(w, line)
+	}
+}
+
+// The records below are what map, mapb, explore, inspect and pokedex emit
+// when the output format is not text. Their json and yaml tags, and their
+// CSV and table columns, are a stable interface for scripts and dashboards:
+// add fields at the end, never rename or remove them. docs/output.md
+// describes them.
+
+// locationRecord is one location area on a page shown by map and mapb.
+type locationRecord struct {
+	Name string `json:"name" yaml:"name"`
+	URL  string `json:"url" yaml:"url"`
+}
+
+func newLocationResult(records []locationRecord) result {
+	return &recordList[locationRecord]{
+		records: records,
+		header:  []string{"name", "url"},
+		row: func(r locationRecord) []string {
+			return []string{r.Name, r.URL}
+		},
+		text: func(w io.Writer, records []locationRecord) {
+			for _, location := range records {
+				fmt.Fprintln(w, location.Name)
+			}
+		},
+	}
+}
+
+// encounterRecord is one Pokemon that explore found in a location area.
+type encounterRecord struct {
+	Location string `json:"location" yaml:"location"`
+	Pokemon  string `json:"pokemon" yaml:"pokemon"`
+}
+
+func newEncounterResult(location string, records []encounterRecord) result {
+	return &recordList[encounterRecord]{
+		records: records,
+		header:  []string{"location", "pokemon"},
+		row: func(r encounterRecord) []string {
+			return []string{r.Location, r.Pokemon}
+		},
+		text: func(w io.Writer, records []encounterRecord) {
+			fmt.Fprintf(w, "Exploring %s...\n", colors.Paint("location", location))
+			if len(records) == 0 {
+				fmt.Fprintln(w, "No Pokemon found in this location")
+				return
+			}
+			fmt.Fprintln(w, "Found Pokemon:")
+			for _, encounter := range records {
+				fmt.Fprintln(w, " - "+colors.Paint("pokemon", encounter.Pokemon))
+			}
+		},
+	}
+}
+
+// pokedexRecord is one caught Pokemon listed by pokedex, which sorts them
+// by name.
+type pokedexRecord struct {
+	Name   string   `json:"name" yaml:"name"`
+	Height int      `json:"height" yaml:"height"`
+	Weight int      `json:"weight" yaml:"weight"`
+	Types  []string `json:"types" yaml:"types"`
+}
+
+func newPokedexResult(records []pokedexRecord) result {
+	return &recordList[pokedexRecord]{
+		records: records,
+		header:  []string{"name", "height", "weight", "types"},
+		row: func(r pokedexRecord) []string {
+			return []string{r.Name, strconv.Itoa(r.Height), strconv.Itoa(r.Weight), strings.Join(r.Types, ";")}
+		},
+		text: func(w io.Writer, records []pokedexRecord) {
+			fmt.Fprintln(w, "Your Pokedex:")
+			for _, pokemon := range records {
+				fmt.Fprintf(w, " - %s\n", pokemon.Name)
+			}
+		},
+	}
+}
+
+// statNames are the stats inspect's CSV and table output have columns for,
+// in order.
+var statNames = []string{"hp", "attack", "defense", "special-attack", "special-defense", "speed"}
+
+// pokemonRecord is the output of inspect.
+type pokemonRecord struct {
+	Name       string         `json:"name" yaml:"name"`
+	Height     int            `json:"height" yaml:"height"`
+	Weight     int            `json:"weight" yaml:"weight"`
+	Stats      map[string]int `json:"stats" yaml:"stats"`
+	Types      []string       `json:"types" yaml:"types"`
+	Species    string         `json:"species" yaml:"species"`
+	Genus      string         `json:"genus" yaml:"genus"`
+	Generation string         `json:"generation" yaml:"generation"`
+	FlavorText string         `json:"flavor_text" yaml:"flavor_text"`
+	Evolutions []string       `json:"evolutions" yaml:"evolutions"`
+}
+
+func newPokemonResult(record pokemonRecord) result {
+	header := []string{"name", "height", "weight"}
+	header = append(header, statNames...)
+	header = append(header, "types", "species", "genus", "generation", "flavor_text", "evolutions")
+
+	return &recordList[pokemonRecord]{
+		records: []pokemonRecord{record},
+		single:  true,
+		header:  header,
+		row: func(r pokemonRecord) []string {
+			row := []string{r.Name, strconv.Itoa(r.Height), strconv.Itoa(r.Weight)}
+			for _, stat := range statNames {
+				row = append(row, strconv.Itoa(r.Stats[stat]))
+			}
+			return append(row,
+				strings.Join(r.Types, ";"),
+				r.Species,
+				r.Genus,
+				r.Generation,
+				r.FlavorText,
+				strings.Join(r.Evolutions, ";"),
+			)
+		},
+		text: func(w io.Writer, records []pokemonRecord) {
+			for _, r := range records {
+				writePokemonText(w, r)
+			}
+		},
+	}
+}
+
+func writePokemonText(w io.Writer, r pokemonRecord) {
+	fmt.Fprintf(w, "Name: %s\n", r.Name)
+	fmt.Fprintf(w, "Height: %d\n", r.Height)
+	fmt.Fprintf(w, "Weight: %d\n", r.Weight)
+	fmt.Fprintf(w, "%s\n", colors.Paint("heading", "Stats:"))
+	for _, stat := range sortedStats(r.Stats) {
+		fmt.Fprintf(w, " -%s: %d\n", colors.Paint("stat:"+stat, stat), r.Stats[stat])
+	}
+	fmt.Fprintf(w, "%s\n", colors.Paint("heading", "Types:"))
+	for _, t := range r.Types {
+		fmt.Fprintf(w, " - %s\n", colors.Paint("type:"+t, t))
+	}
+
+	// The species fields are missing when the API could not be reached.
+	if r.Species != "" {
+		fmt.Fprintf(w, "Species: %s, the %s (%s)\n", r.Species, r.Genus, r.Generation)
+	}
+	if r.FlavorText != "" {
+		fmt.Fprintf(w, "%s\n", r.FlavorText)
+	}
+	if len(r.Evolutions) > 0 {
+		fmt.Fprintf(w, "Evolutions: %s\n", strings.Join(r.Evolutions, " -> "))
+	}
+}
+
+// sortedStats returns the names of stats in statNames order, followed by
+// any others sorted by name.
+func sortedStats(stats map[string]int) []string {
+	var names []string
+	for _, stat := range statNames {
+		if _, ok := stats[stat]; ok {
+			names = append(names, stat)
+		}
+	}
+	for _, stat := range sortedKeys(stats) {
+		if !slices.Contains(statNames, stat) {
+			names = append(names, stat)
+		}
+	}
+	return names
+}
+
+// structured reports whether commands should emit records rather than text.
+func (cfg *config) structured() bool {
+	return cfg.output != "" && cfg.output != render.Text
+}
+
+// show prints a command's result: as text, or as records in the configured
+// output format. Output goes through the pager when one is set.
+func (cfg *config) show(res result) error {
+	var b strings.Builder
+	if _, isText := res.(textResult); isText || !cfg.structured() {
+		res.writeText(&b)
+	} else if err := render.Write(&b, cfg.output, res); err != nil {
+		return err
+	}
+
+	if b.Len() == 0 {
+		return nil
+	}
+	if cfg.paging && cfg.page != nil {
+		cfg.page(b.String())
+		return nil
+	}
+
+	_, err := io.WriteString(cfg.out, b.String())
+	return err
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestStructuredOutput(t *testing.T) {
+	cases := []struct {
+		name     string
+		script   string
+		expected string
+	}{
+		{
+			name:     "map as csv",
+			script:   "set output csv\nmap\n",
+			expected: "name,url\ncanalave-city-area,http://127.0.0.1:",
+		},
+		{
+			name:     "explore as table",
+			script:   "set output table\nexplore pastoria-city-area\n",
+			expected: "LOCATION            POKEMON\npastoria-city-area  tentacool\n",
+		},
+		{
+			name:     "explore with no Pokemon as json",
+			script:   "set output json\nexplore mt-coronet-2f\n",
+			expected: "[]\n",
+		},
+		{
+			name:     "catch as json",
+			script:   "set output json\ncatch pikachu\n",
+			expected: "pikachu was caught!\n",
+		},
+		{
+			name:     "catch through a pipeline",
+			script:   "catch pikachu | grep caught\n",
+			expected: "pikachu was caught!\n",
+		},
+		{
+			name:     "explore through a pipeline",
+			script:   "explore pastoria-city-area | head 1\n",
+			expected: "Exploring pastoria-city-area...\nFound Pokemon:\n - tentacool\n",
+		},
+		{
+			name:     "mapb on the first page as json",
+			script:   "set output json\nmapb\n",
+			expected: "[]\n",
+		},
+		{
+			name:     "pokedex as yaml",
+			script:   "set output yaml\npokedex\n",
+			expected: "- name: pikachu\n  height: 4\n  weight: 60\n  types:\n    - electric\n- name: psyduck\n",
+		},
+		{
+			name:     "back to text",
+			script:   "set output json\nset output text\npokedex\n",
+			expected: "Your Pokedex:\n",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cfg := newTestConfig(t)
+			pokedex["psyduck"] = Pokemon{name: "psyduck", height: 8, weight: 196, types: []string{"water"}}
+			pokedex["pikachu"] = Pokemon{name: "pikachu", height: 4, weight: 60, types: []string{"electric"}}
+
+			status := runScript(cfg, strings.NewReader(c.script))
+			output := takeOutput(cfg)
+			if status != 0 {
+				t.Fatalf("expected status 0, got %d", status)
+			}
+
+			if !strings.HasPrefix(output, c.expected) {
+				t.Errorf("expected output to start with:\n%s\ngot:\n%s", c.expected, output)
+			}
+		})
+	}
+}
+
+func TestInspectJSON(t *testing.T) {
+	cfg := newTestConfig(t)
+	cfg.output = "json"
+	pokedex["pikachu"] = Pokemon{
+		name:   "pikachu",
+		height: 4,
+		weight: 60,
+		types:  []string{"electric"},
+		stats:  map[string]int{"hp": 35, "speed": 90},
+	}
+
+	if err := runCommand(cfg, []string{"inspect", "pikachu"}); err != nil {
+		t.Fatal(err)
+	}
+	output := takeOutput(cfg)
+
+	var record map[string]any
+	if err := json.Unmarshal([]byte(output), &record); err != nil {
+		t.Fatalf("expected JSON, got %q: %v", output, err)
+	}
+	for _, field := range []string{"name", "height", "weight", "stats", "types", "species", "genus", "generation", "flavor_text", "evolutions"} {
+		if _, ok := record[field]; !ok {
+			t.Errorf("expected field %q in %s", field, output)
+		}
+	}
+	if record["genus"] != "Mouse Pokémon" || record["stats"].(map[string]any)["speed"] != 90.0 {
+		t.Errorf("unexpected record: %s", output)
+	}
+}
+
+func TestSetErrors(t *testing.T) {
+	cfg := newTestConfig(t)
+
+	for _, c := range []struct {
+		args []string
+		err  string
+	}{
+		{args: []string{"set", "output", "xml"}, err: `unknown output format "xml": expected one of text, json, yaml, csv, table`},
+		{args: []string{"set", "volume", "11"}, err: `unknown setting "volume"`},
+	} {
+		if err := runCommand(cfg, c.args); err == nil || err.Error() != c.err {
+			t.Errorf("%q: expected error %q, got %v", c.args, c.err, err)
+		}
+	}
+	if cfg.output != "text" {
+		t.Errorf("expected output to stay text, got %s", cfg.output)
+	}
+}
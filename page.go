@@ -0,0 +1,64 @@
+package main
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"pokedexcli/internal/lineedit"
+	"pokedexcli/internal/pager"
+	"strings"
+
+	"github.com/eiannone/keyboard"
+)
+
+// pageOutput writes a command's output to the terminal, through $PAGER or
+// the built-in pager if it does not fit on the screen. The built-in pager
+// reads keys with nextKey.
+func pageOutput(output string, nextKey func() (lineedit.Event, error)) {
+	tty := os.Stdout
+
+	width, height := terminalSize()
+	if width == 0 || height == 0 || pager.Fits(output, width, height) {
+		io.WriteString(tty, output)
+		return
+	}
+
+	if command := strings.Fields(os.Getenv("PAGER")); len(command) > 0 {
+		if err := runExternalPager(command, output); err != nil {
+			io.WriteString(tty, output)
+		}
+		return
+	}
+
+	p := pager.New(tty, output, width, height)
+	p.Start()
+	for {
+		ev, err := nextKey()
+		if err != nil {
+			p.Handle(lineedit.Event{Key: lineedit.KeyEsc})
+			return
+		}
+
+		if ev.Key == lineedit.KeyResize {
+			p.Resize(terminalSize())
+			continue
+		}
+		if p.Handle(ev) {
+			return
+		}
+	}
+}
+
+// runExternalPager shows output with the user's own pager. The terminal
+// leaves raw mode while it runs.
+func runExternalPager(command []string, output string) error {
+	keyboard.Close()
+	defer keyboard.Open()
+
+	cmd := exec.Command(command[0], command[1:]...)
+	cmd.Stdin = strings.NewReader(output)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	return cmd.Run()
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPagedCommands(t *testing.T) {
+	cases := []struct {
+		script string
+		paged  []string
+	}{
+		{script: "map", paged: []string{"canalave-city-area\n"}},
+		{script: "help explore", paged: []string{"Usage: explore <location>\n"}},
+		{script: "map | count", paged: []string{"20\n"}},
+		{script: "catch pikachu", paged: []string{"Throwing a Pokeball at pikachu...\n"}},
+		{script: "set pager off\nmap", paged: nil},
+	}
+
+	for _, c := range cases {
+		t.Run(c.script, func(t *testing.T) {
+			cfg := newTestConfig(t)
+			cfg.paging = true
+
+			var paged []string
+			cfg.page = func(output string) {
+				paged = append(paged, output)
+			}
+
+			for _, line := range strings.Split(c.script, "\n") {
+				if err := runLine(cfg, line, failOnReport(t)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if len(paged) != len(c.paged) {
+				t.Fatalf("expected %d paged outputs, got %q", len(c.paged), paged)
+			}
+			for i, prefix := range c.paged {
+				if !strings.HasPrefix(paged[i], prefix) {
+					t.Errorf("expected paged output to start with %q, got %q", prefix, paged[i])
+				}
+			}
+		})
+	}
+}
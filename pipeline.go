@@ -0,0 +1,361 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// A pipelineFilter takes a command's result after a "|" and returns what
+// is left of it, as in "pokedex | where type=water | sort weight".
+type pipelineFilter struct {
+	name        string
+	description string
+	usage       string
+	minArgs     int
+	maxArgs     int
+	flags       func(*flag.FlagSet)
+	examples    []string
+	apply       func(res result, args commandArgs) (result, error)
+}
+
+var pipelineFilters = []pipelineFilter{
+	{
+		name:        "grep",
+		description: "Keep records that contain a text, ignoring case",
+		usage:       "<text>",
+		minArgs:     1,
+		maxArgs:     1,
+		flags: func(flags *flag.FlagSet) {
+			flags.Bool("invert", false, "keep records that do not contain the text")
+		},
+		examples: []string{"map | grep city", "help | grep --invert pokemon"},
+		apply:    filterGrep,
+	},
+	{
+		name:        "where",
+		description: "Keep records whose field compares true: =, !=, <, <=, > or >=",
+		usage:       "<field><op><value>",
+		minArgs:     1,
+		maxArgs:     -1,
+		examples:    []string{"pokedex | where type=fire", "pokedex | where weight>100", "inspect pikachu | where stats.speed>=90"},
+		apply:       filterWhere,
+	},
+	{
+		name:        "sort",
+		description: "Sort records by a field, numerically if it holds numbers",
+		usage:       "[field]",
+		maxArgs:     1,
+		flags: func(flags *flag.FlagSet) {
+			flags.Bool("reverse", false, "sort in descending order")
+		},
+		examples: []string{"pokedex | sort weight", "pokedex | sort --reverse height"},
+		apply:    filterSort,
+	},
+	{
+		name:        "head",
+		description: "Keep the first records",
+		usage:       "[count]",
+		maxArgs:     1,
+		examples:    []string{"map | head 5"},
+		apply:       filterHead,
+	},
+	{
+		name:        "count",
+		description: "Count the records",
+		examples:    []string{"pokedex | count", "explore pastoria-city-area | count"},
+		apply:       filterCount,
+	},
+}
+
+// whereOperators are the comparisons where understands, longest first so
+// that "<=" is not read as "<".
+var whereOperators = []string{"!=", "<=", ">=", "=", "<", ">"}
+
+// defaultHeadCount is how many records head keeps by default.
+const defaultHeadCount = 10
+
+func lookupFilter(name string) (pipelineFilter, bool) {
+	for _, filter := range pipelineFilters {
+		if filter.name == strings.ToLower(name) {
+			return filter, true
+		}
+	}
+
+	return pipelineFilter{}, false
+}
+
+// command describes the filter as a command, to parse its arguments and
+// show its synopsis.
+func (f pipelineFilter) command() cliCommand {
+	return cliCommand{
+		name:        f.name,
+		description: f.description,
+		usage:       f.usage,
+		minArgs:     f.minArgs,
+		maxArgs:     f.maxArgs,
+		flags:       f.flags,
+		examples:    f.examples,
+	}
+}
+
+// parseFilters turns the stages of a pipeline after the command into
+// functions that apply them.
+func parseFilters(stages [][]string) ([]func(result) (result, error), error) {
+	var filters []func(result) (result, error)
+	for _, stage := range stages {
+		filter, ok := lookupFilter(stage[0])
+		if !ok {
+			names := make([]string, len(pipelineFilters))
+			for i, filter := range pipelineFilters {
+				names[i] = filter.name
+			}
+			return nil, fmt.Errorf("unknown filter %q: expected one of %s", stage[0], strings.Join(names, ", "))
+		}
+
+		args, err := filter.command().parse(stage[1:])
+		if err != nil {
+			return nil, err
+		}
+
+		filters = append(filters, func(res result) (result, error) {
+			return filter.apply(res, args)
+		})
+	}
+
+	return filters, nil
+}
+
+func filterGrep(res result, args commandArgs) (result, error) {
+	text := strings.ToLower(args.arg(0))
+	invert := args.boolFlag("invert")
+
+	var kept []int
+	for i, row := range res.Rows() {
+		if strings.Contains(strings.ToLower(strings.Join(row, " ")), text) != invert {
+			kept = append(kept, i)
+		}
+	}
+
+	return res.subset(kept), nil
+}
+
+func filterWhere(res result, args commandArgs) (result, error) {
+	expr := strings.Join(args.positional, "")
+
+	var field, op, value string
+	for _, candidate := range whereOperators {
+		if i := strings.Index(expr, candidate); i > 0 {
+			field, op, value = expr[:i], candidate, expr[i+len(candidate):]
+			break
+		}
+	}
+	if op == "" {
+		return nil, fmt.Errorf("invalid condition %q: expected <field><op><value>, such as type=fire", expr)
+	}
+
+	var kept []int
+	for i := 0; i < res.Len(); i++ {
+		v, err := recordValue(res, i, field)
+		if err != nil {
+			return nil, err
+		}
+
+		ok, err := compareWhere(v, op, value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", field, err)
+		}
+		if ok {
+			kept = append(kept, i)
+		}
+	}
+
+	return res.subset(kept), nil
+}
+
+// compareWhere reports whether v op value holds. A list field is equal to
+// a value if any of its elements is.
+func compareWhere(v any, op, value string) (bool, error) {
+	if list, ok := v.([]string); ok {
+		found := false
+		for _, element := range list {
+			found = found || strings.EqualFold(element, value)
+		}
+		switch op {
+		case "=":
+			return found, nil
+		case "!=":
+			return !found, nil
+		default:
+			return false, errors.New("a list can only be compared with = or !=")
+		}
+	}
+
+	c := compareValues(v, value)
+	switch op {
+	case "=":
+		return c == 0, nil
+	case "!=":
+		return c != 0, nil
+	case "<":
+		return c < 0, nil
+	case "<=":
+		return c <= 0, nil
+	case ">":
+		return c > 0, nil
+	default:
+		return c >= 0, nil
+	}
+}
+
+func filterSort(res result, args commandArgs) (result, error) {
+	field := args.arg(0)
+	if field == "" {
+		field = res.Header()[0]
+	}
+
+	values := make([]any, res.Len())
+	for i := range values {
+		v, err := recordValue(res, i, field)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = v
+	}
+
+	indexes := make([]int, res.Len())
+	for i := range indexes {
+		indexes[i] = i
+	}
+	reverse := args.boolFlag("reverse")
+	sort.SliceStable(indexes, func(a, b int) bool {
+		c := compareValues(values[indexes[a]], values[indexes[b]])
+		if reverse {
+			return c > 0
+		}
+		return c < 0
+	})
+
+	return res.subset(indexes), nil
+}
+
+func filterHead(res result, args commandArgs) (result, error) {
+	n := defaultHeadCount
+	if arg := args.arg(0); arg != "" {
+		var err error
+		n, err = strconv.Atoi(arg)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid count %q", arg)
+		}
+	}
+
+	indexes := make([]int, min(n, res.Len()))
+	for i := range indexes {
+		indexes[i] = i
+	}
+
+	return res.subset(indexes), nil
+}
+
+// countRecord is the output of the count filter.
+type countRecord struct {
+	Count int `json:"count" yaml:"count"`
+}
+
+func filterCount(res result, args commandArgs) (result, error) {
+	return &recordList[countRecord]{
+		records: []countRecord{{Count: res.Len()}},
+		single:  true,
+		header:  []string{"count"},
+		row: func(r countRecord) []string {
+			return []string{strconv.Itoa(r.Count)}
+		},
+		text: func(w io.Writer, records []countRecord) {
+			for _, r := range records {
+				fmt.Fprintln(w, r.Count)
+			}
+		},
+	}, nil
+}
+
+// recordValue returns the named field of record i. A name without an "s"
+// finds a list field with one, so "type" finds "types", and
+// "stats.speed" finds the speed entry of the stats field.
+func recordValue(res result, i int, name string) (any, error) {
+	name = strings.ToLower(name)
+	field, key, hasKey := strings.Cut(name, ".")
+
+	v, ok := res.field(i, field)
+	if !ok {
+		v, ok = res.field(i, field+"s")
+	}
+	if !ok {
+		return nil, fmt.Errorf("no field %q: expected one of %s", name, strings.Join(res.Header(), ", "))
+	}
+
+	m, isMap := v.(map[string]int)
+	switch {
+	case isMap && hasKey:
+		return m[key], nil
+	case isMap:
+		return nil, fmt.Errorf("field %q holds several values: name one, such as %s.%s", field, field, statNames[0])
+	case hasKey:
+		return nil, fmt.Errorf("field %q has no %q", field, key)
+	}
+
+	return v, nil
+}
+
+// compareValues orders two field values: numerically if both are numbers,
+// otherwise as text, ignoring case.
+func compareValues(a, b any) int {
+	x, xNumber := numberValue(a)
+	y, yNumber := numberValue(b)
+	if xNumber && yNumber {
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		default:
+			return 0
+		}
+	}
+
+	return strings.Compare(strings.ToLower(textValue(a)), strings.ToLower(textValue(b)))
+}
+
+func numberValue(v any) (float64, bool) {
+	switch v := v.(type) {
+	case int:
+		return float64(v), true
+	case string:
+		n, err := strconv.ParseFloat(v, 64)
+		return n, err == nil
+	}
+	return 0, false
+}
+
+func textValue(v any) string {
+	if list, ok := v.([]string); ok {
+		return strings.Join(list, ";")
+	}
+	return fmt.Sprint(v)
+}
+
+// printFilterList writes every filter's synopsis and description.
+func printFilterList(w io.Writer) {
+	width := 0
+	for _, filter := range pipelineFilters {
+		width = max(width, len(filter.command().synopsis()))
+	}
+
+	fmt.Fprintln(w, "\nFilters (after |):")
+	for _, filter := range pipelineFilters {
+		fmt.Fprintf(w, "  %-*s  %s\n", width, filter.command().synopsis(), filter.description)
+	}
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPipelines(t *testing.T) {
+	cases := []struct {
+		name     string
+		line     string
+		output   string
+		contains string
+		err      string
+	}{
+		{
+			name:   "where with a list field",
+			line:   "pokedex | where type=water",
+			output: "Your Pokedex:\n - psyduck\n - squirtle\n",
+		},
+		{
+			name:   "where then sort",
+			line:   "pokedex | where type=water | sort --reverse weight",
+			output: "Your Pokedex:\n - psyduck\n - squirtle\n",
+		},
+		{
+			name:   "numeric sort",
+			line:   "pokedex | sort weight",
+			output: "Your Pokedex:\n - pikachu\n - squirtle\n - psyduck\n",
+		},
+		{
+			name:   "numeric where",
+			line:   "pokedex | where weight < 100",
+			output: "Your Pokedex:\n - pikachu\n - squirtle\n",
+		},
+		{
+			name:   "not equal",
+			line:   "pokedex | where type!=water",
+			output: "Your Pokedex:\n - pikachu\n",
+		},
+		{
+			name:   "head",
+			line:   "pokedex | sort --reverse height | head 1",
+			output: "Your Pokedex:\n - psyduck\n",
+		},
+		{
+			name:   "count",
+			line:   "pokedex | count",
+			output: "3\n",
+		},
+		{
+			name:   "grep records",
+			line:   "map | grep eterna | count",
+			output: "2\n",
+		},
+		{
+			name:     "grep text",
+			line:     "help | grep catch",
+			contains: "  catch <pokemon>",
+		},
+		{
+			name:   "grep inverted",
+			line:   "pokedex | grep --invert water",
+			output: "Your Pokedex:\n - pikachu\n",
+		},
+		{
+			name:   "map field",
+			line:   "inspect pikachu | where stats.speed>=90 | count",
+			output: "1\n",
+		},
+		{
+			name:   "count of a command without output",
+			line:   "set output text | count",
+			output: "0\n",
+		},
+		{
+			name: "unknown filter",
+			line: "pokedex | frobnicate",
+			err:  `unknown filter "frobnicate": expected one of grep, where, sort, head, count`,
+		},
+		{
+			name: "empty stage",
+			line: "pokedex |",
+			err:  "empty command in pipeline",
+		},
+		{
+			name: "missing operator",
+			line: "pokedex | where type",
+			err:  `invalid condition "type": expected <field><op><value>, such as type=fire`,
+		},
+		{
+			name: "unknown field",
+			line: "pokedex | sort color",
+			err:  `no field "color": expected one of name, height, weight, types`,
+		},
+		{
+			name: "ordering a list",
+			line: "pokedex | where types>water",
+			err:  "types: a list can only be compared with = or !=",
+		},
+		{
+			name: "map without a key",
+			line: "inspect pikachu | sort stats",
+			err:  `field "stats" holds several values: name one, such as stats.hp`,
+		},
+		{
+			name: "bad count",
+			line: "pokedex | head many",
+			err:  `invalid count "many"`,
+		},
+		{
+			name: "filter arity",
+			line: "pokedex | count 3",
+			err:  "too many arguments (usage: count)",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cfg := newTestConfig(t)
+			pokedex["pikachu"] = Pokemon{name: "pikachu", height: 4, weight: 60, types: []string{"electric"}, stats: map[string]int{"speed": 90}}
+			pokedex["psyduck"] = Pokemon{name: "psyduck", height: 8, weight: 196, types: []string{"water"}}
+			pokedex["squirtle"] = Pokemon{name: "squirtle", height: 5, weight: 90, types: []string{"water"}}
+
+			err := runLine(cfg, c.line, failOnReport(t))
+			output := takeOutput(cfg)
+			if c.err != "" {
+				if err == nil || err.Error() != c.err {
+					t.Errorf("expected error %q, got %v", c.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if c.contains != "" {
+				if !strings.Contains(output, c.contains) {
+					t.Errorf("expected output to contain %q, got:\n%s", c.contains, output)
+				}
+				return
+			}
+			if output != c.output {
+				t.Errorf("expected output %q, got %q", c.output, output)
+			}
+		})
+	}
+}
+
+func TestPipelineStructuredOutput(t *testing.T) {
+	cfg := newTestConfig(t)
+	cfg.output = "json"
+	pokedex["psyduck"] = Pokemon{name: "psyduck", height: 8, weight: 196, types: []string{"water"}}
+	pokedex["pikachu"] = Pokemon{name: "pikachu", height: 4, weight: 60, types: []string{"electric"}}
+
+	if err := runLine(cfg, "pokedex | where type=water", failOnReport(t)); err != nil {
+		t.Fatal(err)
+	}
+	if err := runLine(cfg, "pokedex | where type=fire", failOnReport(t)); err != nil {
+		t.Fatal(err)
+	}
+	if err := runLine(cfg, "pokedex | count", failOnReport(t)); err != nil {
+		t.Fatal(err)
+	}
+	output := takeOutput(cfg)
+
+	expected := `[
+  {
+    "name": "psyduck",
+    "height": 8,
+    "weight": 196,
+    "types": [
+      "water"
+    ]
+  }
+]
+[]
+{
+  "count": 2
+}
+`
+	if output != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, output)
+	}
+}
@@ -0,0 +1,278 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"pokedexcli/internal/atomicfile"
+	"pokedexcli/internal/theme"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// defaultProfile is the profile in use until another is switched to. Its
+// files are the ones in the config directory itself, where they were
+// before there were profiles.
+const defaultProfile = "default"
+
+// profileName matches the names profile new accepts.
+var profileName = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// profilesDir holds a directory for each profile but the default one, or
+// is "" if there is no config directory.
+func profilesDir() string {
+	dir := configDir()
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, "profiles")
+}
+
+// profileDir returns the directory a profile keeps its config file,
+// history and caught Pokemon in, or "" if there is no config directory.
+func profileDir(name string) string {
+	if name == defaultProfile {
+		return configDir()
+	}
+	if dir := profilesDir(); dir != "" {
+		return filepath.Join(dir, name)
+	}
+	return ""
+}
+
+// activeProfilePath is the file naming the profile in use.
+func activeProfilePath() string {
+	dir := configDir()
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, "profile")
+}
+
+// activeProfile returns the profile in use, or the default profile if
+// none was switched to or the one that was no longer exists.
+func activeProfile() string {
+	path := activeProfilePath()
+	if path == "" {
+		return defaultProfile
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return defaultProfile
+	}
+	name := strings.TrimSpace(string(data))
+	if !profileExists(name) {
+		return defaultProfile
+	}
+
+	return name
+}
+
+func profileExists(name string) bool {
+	if name == defaultProfile {
+		return true
+	}
+	if !profileName.MatchString(name) {
+		return false
+	}
+	info, err := os.Stat(profileDir(name))
+	return err == nil && info.IsDir()
+}
+
+// profileNames returns the default profile followed by the others, sorted.
+func profileNames() ([]string, error) {
+	names := []string{defaultProfile}
+
+	dir := profilesDir()
+	if dir == "" {
+		return names, nil
+	}
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return names, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() && profileName.MatchString(entry.Name()) && entry.Name() != defaultProfile {
+			names = append(names, entry.Name())
+		}
+	}
+	slices.Sort(names[1:])
+
+	return names, nil
+}
+
+// loadProfileSettings reads a profile's config file, or the one at
+// configPath if it is not "", and returns its settings and the path of the
+// file. The profile's history and save file are in its directory unless
+// the file says otherwise.
+func loadProfileSettings(name, configPath string) (settings, string, error) {
+	dir := profileDir(name)
+	path := configPath
+	if path == "" && dir != "" {
+		path = filepath.Join(dir, "config.json")
+	}
+	if path == "" {
+		s, err := loadSettingsOver("", defaultSettingsIn(""))
+		return s, "", err
+	}
+
+	s, err := loadSettingsOver(path, defaultSettingsIn(dir))
+	if err != nil {
+		return s, path, fmt.Errorf("%s: %w", path, err)
+	}
+	return s, path, nil
+}
+
+// useSettings switches the session to a profile's settings, read from the
+// config file at settingsPath: its prompt and theme, aliases and macros,
+// history and caught Pokemon. The backend and cache stay as they were
+// when the program started. If the prompt or theme is invalid nothing
+// changes and the error is returned. Otherwise problems loading the
+// history or caught Pokemon are passed to warn.
+func (cfg *config) useSettings(s settings, settingsPath string, warn func(error)) error {
+	prompt, err := newPromptTemplate(s.Prompt)
+	if err != nil {
+		return err
+	}
+	outColors, ok := theme.Get(s.Theme, theme.Enabled(os.Stdout))
+	if !ok {
+		return fmt.Errorf("unknown theme %q: expected one of %s", s.Theme, strings.Join(theme.Names(), ", "))
+	}
+
+	colors = outColors
+	errColors, _ = theme.Get(s.Theme, theme.Enabled(os.Stderr))
+	cfg.prompt = prompt
+
+	cfg.settingsPath = settingsPath
+	loadUserCommands(cfg, s)
+
+	cfg.historyPath = s.HistoryFile
+	cfg.history.Clear()
+	cfg.history.Configure(s.HistorySize, s.HistoryDedupe)
+	if s.HistoryFile != "" {
+		if err := cfg.history.Load(s.HistoryFile); err != nil {
+			warn(fmt.Errorf("loading history: %w", err))
+		}
+	}
+
+	pokedex = make(map[string]Pokemon)
+	cfg.savePath = ""
+	cfg.lead = ""
+	if s.SaveFile != "" {
+		if err := loadSaveFile(cfg, s.SaveFile); err != nil {
+			warn(err)
+		}
+	}
+
+	return nil
+}
+
+// switchProfile makes name the profile in use, for this session and the
+// next ones.
+func switchProfile(cfg *config, name string) error {
+	s, path, err := loadProfileSettings(name, "")
+	if err != nil {
+		return err
+	}
+
+	var warnings []error
+	if err := cfg.useSettings(s, path, func(err error) { warnings = append(warnings, err) }); err != nil {
+		return err
+	}
+	cfg.profile = name
+
+	if active := activeProfilePath(); active != "" {
+		if err := atomicfile.Write(active, []byte(name+"\n")); err != nil {
+			warnings = append(warnings, err)
+		}
+	}
+
+	return errors.Join(warnings...)
+}
+
+func commandProfile(cfg *config, args commandArgs) (result, error) {
+	action := strings.ToLower(args.arg(0))
+	name := strings.ToLower(args.arg(1))
+	if action == "" {
+		action = "list"
+	}
+
+	wantsName := action == "new" || action == "switch" || action == "delete"
+	if wantsName != (len(args.positional) == 2) {
+		return nil, usageError{commands["profile"], "expected list, or new, switch or delete and a name"}
+	}
+	if wantsName && cfg.replaying {
+		return nil, errors.New("cannot change profiles from within a replay")
+	}
+	if wantsName && profilesDir() == "" {
+		return nil, errors.New("profiles need a config directory, and there is none")
+	}
+
+	switch action {
+	case "list":
+		names, err := profileNames()
+		if err != nil {
+			return nil, err
+		}
+		lines := textResult{}
+		for _, name := range names {
+			if name == cfg.profile {
+				lines = append(lines, "* "+name)
+			} else {
+				lines = append(lines, "  "+name)
+			}
+		}
+		return lines, nil
+	case "new":
+		if !profileName.MatchString(name) {
+			return nil, fmt.Errorf("%q is not a valid name: use letters, digits, - and _", name)
+		}
+		if profileExists(name) {
+			return nil, fmt.Errorf("profile %s already exists", name)
+		}
+		if err := os.MkdirAll(profileDir(name), 0o755); err != nil {
+			return nil, err
+		}
+		if err := switchProfile(cfg, name); err != nil {
+			return nil, err
+		}
+		return textResult{"Created profile " + name + " and switched to it"}, nil
+	case "switch":
+		if !profileExists(name) {
+			return nil, fmt.Errorf("no profile named %s", name)
+		}
+		if name == cfg.profile {
+			return textResult{"Already using profile " + name}, nil
+		}
+		if err := switchProfile(cfg, name); err != nil {
+			return nil, err
+		}
+		return textResult{fmt.Sprintf("Switched to profile %s: %d Pokemon caught", name, len(pokedex))}, nil
+	case "delete":
+		switch {
+		case name == defaultProfile:
+			return nil, errors.New("the default profile cannot be deleted")
+		case !profileExists(name):
+			return nil, fmt.Errorf("no profile named %s", name)
+		case name == cfg.profile:
+			return nil, fmt.Errorf("%s is the profile in use: switch to another one first", name)
+		}
+		if cfg.confirm != nil && !cfg.confirm(fmt.Sprintf("Delete profile %s with its caught Pokemon, history and settings?", name)) {
+			return nil, nil
+		}
+		if err := os.RemoveAll(profileDir(name)); err != nil {
+			return nil, err
+		}
+		return textResult{"Deleted profile " + name}, nil
+	}
+
+	return nil, usageError{commands["profile"], fmt.Sprintf("unknown action %q", action)}
+}
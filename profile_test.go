@@ -0,0 +1,199 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// useTempConfigDir points the config directory at an empty temporary one.
+func useTempConfigDir(t *testing.T) string {
+	t.Helper()
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+	t.Setenv("AppData", filepath.Join(home, "AppData"))
+
+	return configDir()
+}
+
+// newProfileTestConfig returns a test config using the default profile's
+// files.
+func newProfileTestConfig(t *testing.T) *config {
+	t.Helper()
+
+	cfg := newTestConfig(t)
+	cfg.profile = defaultProfile
+	s, path, err := loadProfileSettings(defaultProfile, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.useSettings(s, path, func(err error) { t.Error(err) }); err != nil {
+		t.Fatal(err)
+	}
+	return cfg
+}
+
+func TestProfiles(t *testing.T) {
+	dir := useTempConfigDir(t)
+	cfg := newProfileTestConfig(t)
+
+	run := func(line string) string {
+		t.Helper()
+		if err := runLine(cfg, line, failOnReport(t)); err != nil {
+			t.Fatalf("%s: %v", line, err)
+		}
+		return takeOutput(cfg)
+	}
+
+	run("catch pikachu")
+	run("alias fish explore pastoria-city-area")
+
+	if output := run("profile new ash"); output != "Created profile ash and switched to it\n" {
+		t.Errorf("unexpected output %q", output)
+	}
+	if len(pokedex) != 0 || len(cfg.aliases) != 0 {
+		t.Errorf("expected a new profile to start empty, got %v and %v", pokedex, cfg.aliases)
+	}
+	if cfg.savePath != filepath.Join(dir, "profiles", "ash", "pokedex.json") {
+		t.Errorf("expected the new profile to save in its directory, got %s", cfg.savePath)
+	}
+	if activeProfile() != "ash" {
+		t.Errorf("expected ash to be the active profile, got %s", activeProfile())
+	}
+
+	run("catch pikachu")
+	run("alias gym explore canalave-city-area")
+	if output := run("profile"); output != "  default\n* ash\n" {
+		t.Errorf("unexpected profile list %q", output)
+	}
+
+	if output := run("profile switch default"); output != "Switched to profile default: 1 Pokemon caught\n" {
+		t.Errorf("unexpected output %q", output)
+	}
+	if _, ok := cfg.aliases["fish"]; !ok || len(cfg.aliases) != 1 {
+		t.Errorf("expected the default profile's aliases back, got %v", cfg.aliases)
+	}
+	if activeProfile() != defaultProfile {
+		t.Errorf("expected the default profile to be active, got %s", activeProfile())
+	}
+
+	if output := run("profile delete ash"); output != "Deleted profile ash\n" {
+		t.Errorf("unexpected output %q", output)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "profiles", "ash")); !os.IsNotExist(err) {
+		t.Errorf("expected the profile's directory to be removed, got %v", err)
+	}
+}
+
+func TestProfileHistorySettings(t *testing.T) {
+	dir := useTempConfigDir(t)
+	cfg := newProfileTestConfig(t)
+
+	ash := filepath.Join(dir, "profiles", "ash")
+	if err := os.MkdirAll(ash, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	config := `{"history_size": 2, "history_dedupe": false}`
+	if err := os.WriteFile(filepath.Join(ash, "config.json"), []byte(config), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := switchProfile(cfg, "ash"); err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{"help", "map", "map"} {
+		cfg.history.Add(line)
+	}
+	if expected := []string{"map", "map"}; !reflect.DeepEqual(cfg.history.Entries(), expected) {
+		t.Errorf("expected ash's history settings, got %v", cfg.history.Entries())
+	}
+}
+
+func TestProfileSettingsWithConfigFile(t *testing.T) {
+	dir := useTempConfigDir(t)
+	misty := filepath.Join(dir, "profiles", "misty")
+	if err := os.MkdirAll(misty, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	config := filepath.Join(t.TempDir(), "c.json")
+	if err := os.WriteFile(config, []byte(`{"theme": "mono"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	s, path, err := loadProfileSettings("misty", config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path != config || s.Theme != "mono" {
+		t.Errorf("expected the settings from %s, got %+v from %s", config, s, path)
+	}
+	if s.SaveFile != filepath.Join(misty, "pokedex.json") || s.HistoryFile != filepath.Join(misty, "history") {
+		t.Errorf("expected misty's save and history files, got %s and %s", s.SaveFile, s.HistoryFile)
+	}
+}
+
+func TestProfileErrors(t *testing.T) {
+	useTempConfigDir(t)
+
+	cases := []struct {
+		line string
+		err  string
+	}{
+		{line: "profile switch misty", err: "no profile named misty"},
+		{line: "profile delete default", err: "the default profile cannot be deleted"},
+		{line: "profile delete misty", err: "no profile named misty"},
+		{line: "profile new default", err: "profile default already exists"},
+		{line: "profile new ../misty", err: `"../misty" is not a valid name: use letters, digits, - and _`},
+		{line: "profile new", err: "expected list, or new, switch or delete and a name (usage: profile [list | new <name> | switch <name> | delete <name>])"},
+		{line: "profile rename misty", err: "expected list, or new, switch or delete and a name (usage: profile [list | new <name> | switch <name> | delete <name>])"},
+		{line: "profile rename", err: `unknown action "rename" (usage: profile [list | new <name> | switch <name> | delete <name>])`},
+	}
+
+	for _, c := range cases {
+		t.Run(c.line, func(t *testing.T) {
+			cfg := newProfileTestConfig(t)
+			err := runLine(cfg, c.line, failOnReport(t))
+			if err == nil || err.Error() != c.err {
+				t.Errorf("expected error %q, got %v", c.err, err)
+			}
+		})
+	}
+
+	cfg := newProfileTestConfig(t)
+	if err := runLine(cfg, "profile new misty", failOnReport(t)); err != nil {
+		t.Fatal(err)
+	}
+	err := runLine(cfg, "profile delete misty", failOnReport(t))
+	if err == nil || !strings.Contains(err.Error(), "is the profile in use") {
+		t.Errorf("expected an error deleting the profile in use, got %v", err)
+	}
+}
+
+func TestActiveProfile(t *testing.T) {
+	dir := useTempConfigDir(t)
+
+	if name := activeProfile(); name != defaultProfile {
+		t.Errorf("expected the default profile without a profile file, got %s", name)
+	}
+
+	if err := os.MkdirAll(filepath.Join(dir, "profiles", "misty"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(activeProfilePath(), []byte("misty\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if name := activeProfile(); name != "misty" {
+		t.Errorf("expected misty, got %s", name)
+	}
+
+	if err := os.WriteFile(activeProfilePath(), []byte("brock\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if name := activeProfile(); name != defaultProfile {
+		t.Errorf("expected the default profile when the active one is gone, got %s", name)
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"pokedexcli/internal/theme"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// defaultPromptTemplate is the prompt unless the config file sets one, and
+// defaultPrompt the text used when a prompt template fails.
+const defaultPromptTemplate = `{{color "prompt" "Pokedex >"}} `
+const defaultPrompt = "Pokedex > "
+
+// colors styles what commands print to stdout, and errColors what is
+// printed to stderr. Both color nothing unless main enables them.
+var colors = &theme.Theme{}
+var errColors = &theme.Theme{}
+
+// promptData is what a prompt template can show.
+type promptData struct {
+	// Location is the last location explored, or "" before the first.
+	Location string
+	// Caught is how many Pokemon are in the Pokedex.
+	Caught int
+	// Lead is the Pokemon leading the party: the first one caught.
+	Lead string
+	// Time is when the prompt is shown, for templates such as
+	// `{{.Time.Format "15:04"}} > `.
+	Time time.Time
+	// Profile is the trainer profile in use.
+	Profile string
+}
+
+// newPromptTemplate parses a prompt template such as
+// `{{color "location" .Location}} {{.Caught}} > `. The color function
+// paints text in a theme style or basic color.
+func newPromptTemplate(text string) (*template.Template, error) {
+	funcs := template.FuncMap{
+		"color": func(style string, text any) string {
+			return colors.Paint(style, fmt.Sprint(text))
+		},
+	}
+
+	tmpl, err := template.New("prompt").Funcs(funcs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("prompt: %w", err)
+	}
+
+	return tmpl, nil
+}
+
+// renderPrompt fills in the prompt template for the current state, falling
+// back to the default prompt if the template fails.
+func renderPrompt(tmpl *template.Template, cfg *config) string {
+	data := promptData{
+		Location: cfg.location,
+		Caught:   len(pokedex),
+		Lead:     cfg.lead,
+		Time:     cfg.now(),
+		Profile:  cfg.profile,
+	}
+
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return defaultPrompt
+	}
+
+	return b.String()
+}
+
+// errorText formats err the way the REPL and scripts report it.
+func errorText(t *theme.Theme, err error) string {
+	return t.Paint("error", "Error: "+err.Error())
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"errors"
+	"pokedexcli/internal/theme"
+	"testing"
+)
+
+func TestRenderPrompt(t *testing.T) {
+	cases := []struct {
+		name     string
+		template string
+		colored  bool
+		expected string
+	}{
+		{
+			name:     "default",
+			template: defaultPromptTemplate,
+			expected: "Pokedex > ",
+		},
+		{
+			name:     "default in color",
+			template: defaultPromptTemplate,
+			colored:  true,
+			expected: "\x1b[1;36mPokedex >\x1b[0m ",
+		},
+		{
+			name:     "state",
+			template: "{{.Location}} {{.Caught}} {{.Lead}} > ",
+			expected: "eterna-forest-area 2 pikachu > ",
+		},
+		{
+			name:     "colored state",
+			template: `{{color "location" .Location}} {{color "count" .Caught}} > `,
+			colored:  true,
+			expected: "\x1b[32meterna-forest-area\x1b[0m \x1b[33m2\x1b[0m > ",
+		},
+		{
+			name:     "time",
+			template: `{{.Time.Format "15:04"}} > `,
+			expected: "09:30 > ",
+		},
+		{
+			name:     "profile",
+			template: "{{.Profile}} > ",
+			expected: "ash > ",
+		},
+		{
+			name:     "failing template",
+			template: "{{.Region}} > ",
+			expected: defaultPrompt,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cfg := newTestConfig(t)
+			cfg.location = "eterna-forest-area"
+			cfg.lead = "pikachu"
+			cfg.profile = "ash"
+			pokedex["pikachu"] = Pokemon{name: "pikachu"}
+			pokedex["psyduck"] = Pokemon{name: "psyduck"}
+
+			if c.colored {
+				colors, _ = theme.Get("default", true)
+				t.Cleanup(func() { colors = &theme.Theme{} })
+			}
+
+			tmpl, err := newPromptTemplate(c.template)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if actual := renderPrompt(tmpl, cfg); actual != c.expected {
+				t.Errorf("expected %q, got %q", c.expected, actual)
+			}
+		})
+	}
+}
+
+func TestNewPromptTemplateError(t *testing.T) {
+	if _, err := newPromptTemplate("{{.Location"); err == nil {
+		t.Errorf("expected an error for an unclosed action")
+	}
+}
+
+func TestErrorText(t *testing.T) {
+	colored, _ := theme.Get("default", true)
+	if actual := errorText(colored, errors.New("oops")); actual != "\x1b[31mError: oops\x1b[0m" {
+		t.Errorf("unexpected colored error: %q", actual)
+	}
+	if actual := errorText(&theme.Theme{}, errors.New("oops")); actual != "Error: oops" {
+		t.Errorf("unexpected plain error: %q", actual)
+	}
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"pokedexcli/internal/lineedit"
+	"strings"
+)
+
+// continuationPrompt is shown for the lines that continue a command line
+// ending in a backslash.
+const continuationPrompt = "> "
+
+// Repl is the read-eval-print loop. It reads command lines from an input,
+// runs them and writes what they print to the config's output, so it can
+// be driven without a terminal.
+type Repl struct {
+	cfg   *config
+	input replInput
+}
+
+// replInput is where the REPL reads command lines from.
+type replInput interface {
+	// readLine shows prompt and returns the next line, or io.EOF when there
+	// are no more.
+	readLine(prompt string) (string, error)
+}
+
+func newRepl(cfg *config, input replInput) *Repl {
+	return &Repl{cfg: cfg, input: input}
+}
+
+// Run reads and runs command lines until the user exits. The end of the
+// input, such as Ctrl-D on an empty line, exits the same way.
+func (r *Repl) Run() error {
+	for {
+		input, err := r.readInput()
+		if errors.Is(err, io.EOF) {
+			err = runCommand(r.cfg, []string{"exit"})
+			if errors.Is(err, errExit) {
+				return nil
+			}
+			return err
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := r.eval(input); errors.Is(err, errExit) {
+			return nil
+		}
+	}
+}
+
+// readInput reads a command line, reading on while it ends in a
+// backslash.
+func (r *Repl) readInput() (string, error) {
+	input, err := r.input.readLine(renderPrompt(r.cfg.prompt, r.cfg))
+	for err == nil && continuesLine(input) {
+		var next string
+		next, err = r.input.readLine(continuationPrompt)
+		input = input[:len(input)-1] + next
+	}
+	return input, err
+}
+
+// eval runs one command line, reporting any error to the output. It
+// returns errExit if the line asked to exit.
+func (r *Repl) eval(input string) error {
+	out := r.cfg.out
+
+	input, ok, err := expandHistory(input, r.cfg.history)
+	if err != nil {
+		fmt.Fprintln(out, err)
+		return nil
+	}
+	if ok {
+		fmt.Fprintln(out, input)
+	}
+
+	links, err := splitCommandLine(input)
+	if err != nil {
+		fmt.Fprintln(out, errorText(colors, err))
+		return nil
+	}
+	if len(links) == 1 && links[0].empty() {
+		return nil
+	}
+
+	for _, link := range links {
+		args := link.stages[0]
+		if len(args) == 0 || r.cfg.isUserCommand(args[0]) {
+			continue
+		}
+		if _, ok := lookupCommand(args[0]); !ok {
+			if suggestion, ok := suggestCommand(args[0]); ok {
+				fmt.Fprintf(out, "Unknown command. Did you mean %s?\n", suggestion)
+			} else {
+				fmt.Fprintln(out, "Unknown command")
+			}
+			return nil
+		}
+	}
+
+	r.cfg.history.Add(strings.TrimSpace(input))
+	if r.cfg.historyPath != "" {
+		if err := r.cfg.history.Save(r.cfg.historyPath); err != nil {
+			fmt.Fprintf(out, "Error: saving history: %s\n", err)
+		}
+	}
+
+	report, recorded := r.cfg.recordLine(input, func(err error) {
+		fmt.Fprintln(out, errorText(colors, err))
+	})
+	defer recorded()
+
+	err = runChain(r.cfg, links, report)
+	if errors.Is(err, errExit) {
+		return err
+	}
+	if err != nil {
+		report(err)
+	}
+
+	return nil
+}
+
+// keyInput reads lines by feeding key events to a line editor, which
+// gives them history and completion. Ctrl-D on an empty line ends the
+// input.
+type keyInput struct {
+	editor *lineedit.Editor
+	// nextKey returns the next key the user pressed, or io.EOF when the
+	// program should stop.
+	nextKey func() (lineedit.Event, error)
+	// width returns the terminal's width, or 0 if it is unknown. It may be
+	// nil.
+	width func() int
+}
+
+func (in *keyInput) readLine(prompt string) (string, error) {
+	in.editor.SetPrompt(prompt)
+	if in.width != nil {
+		in.editor.SetWidth(in.width())
+	}
+	in.editor.Start()
+
+	for {
+		ev, err := in.nextKey()
+		if err != nil {
+			// Leave the cursor on a fresh row for whatever is printed next.
+			in.editor.Handle(lineedit.Event{Key: lineedit.KeyEnter})
+			return "", err
+		}
+
+		switch {
+		case ev.Key == lineedit.KeyCtrlD && in.editor.Line() == "":
+			in.editor.Handle(lineedit.Event{Key: lineedit.KeyEnter})
+			return "", io.EOF
+		case ev.Key == lineedit.KeyResize && in.width != nil:
+			in.editor.SetWidth(in.width())
+		}
+
+		if line, done := in.editor.Handle(ev); done {
+			return line, nil
+		}
+	}
+}
+
+// lineInput reads whole lines, showing the prompt before each one.
+type lineInput struct {
+	scanner *bufio.Scanner
+	out     io.Writer
+}
+
+func newLineInput(r io.Reader, out io.Writer) *lineInput {
+	return &lineInput{scanner: bufio.NewScanner(r), out: out}
+}
+
+func (in *lineInput) readLine(prompt string) (string, error) {
+	io.WriteString(in.out, prompt)
+
+	if !in.scanner.Scan() {
+		if err := in.scanner.Err(); err != nil {
+			return "", err
+		}
+		return "", io.EOF
+	}
+
+	return in.scanner.Text(), nil
+}
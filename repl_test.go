@@ -1,6 +1,15 @@
 package main
 
-import "testing"
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math/rand"
+	"pokedexcli/internal/lineedit"
+	"reflect"
+	"strings"
+	"testing"
+)
 
 func TestCleanInput(t *testing.T) {
 	cases := []struct {
@@ -38,3 +47,292 @@ func TestCleanInput(t *testing.T) {
 		}
 	}
 }
+
+// newTestRepl returns a REPL over cfg that reads from input and writes to
+// the returned buffer.
+func newTestRepl(t *testing.T, cfg *config, input func(out io.Writer) replInput) (*Repl, *bytes.Buffer) {
+	t.Helper()
+
+	var out bytes.Buffer
+	cfg.out = &out
+
+	prompt, err := newPromptTemplate(defaultPromptTemplate)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg.prompt = prompt
+
+	return newRepl(cfg, input(&out)), &out
+}
+
+func TestReplCommands(t *testing.T) {
+	cases := []struct {
+		name     string
+		lines    []string
+		contains []string
+		absent   []string
+	}{
+		{
+			name:     "help",
+			lines:    []string{"help"},
+			contains: []string{"Pokedex > Welcome to the Pokedex!\n"},
+		},
+		{
+			name:     "exit stops reading",
+			lines:    []string{"exit", "map"},
+			contains: []string{"Closing the Pokedex... Goodbye!\n"},
+			absent:   []string{"canalave-city-area"},
+		},
+		{
+			name:     "map",
+			lines:    []string{"map"},
+			contains: []string{"canalave-city-area\n"},
+		},
+		{
+			name:     "mapb",
+			lines:    []string{"mapb"},
+			contains: []string{"you're on the first page\n"},
+		},
+		{
+			name:     "explore",
+			lines:    []string{"explore pastoria-city-area"},
+			contains: []string{"Exploring pastoria-city-area...\n", "Found Pokemon:\n", " - tentacool\n"},
+		},
+		{
+			name:     "catch",
+			lines:    []string{"catch pikachu"},
+			contains: []string{"Throwing a Pokeball at pikachu...\n"},
+		},
+		{
+			name:     "inspect",
+			lines:    []string{"inspect pikachu"},
+			contains: []string{"Error: you have not caught pikachu\n"},
+		},
+		{
+			name:     "pokedex",
+			lines:    []string{"pokedex"},
+			contains: []string{"Your Pokedex:\n"},
+		},
+		{
+			name:     "history",
+			lines:    []string{"map", "history"},
+			contains: []string{"    1  map\n    2  history\n"},
+		},
+		{
+			name:     "history expansion",
+			lines:    []string{"!!"},
+			contains: []string{"!!: event not found\n"},
+		},
+		{
+			name:     "alias",
+			lines:    []string{"alias m map", "alias", "m"},
+			contains: []string{"m: map\n", "canalave-city-area\n"},
+		},
+		{
+			name:     "macro",
+			lines:    []string{"macro twice map mapb", "macro"},
+			contains: []string{"twice: map; mapb\n"},
+		},
+		{
+			name:     "set",
+			lines:    []string{"set output json", "set"},
+			contains: []string{"output: json\npager: off\n"},
+		},
+		{
+			name:     "sync",
+			lines:    []string{"sync"},
+			contains: []string{"Syncing location-area", "Error: sync requires an on-disk cache\n"},
+		},
+		{
+			name:     "unknown command with a suggestion",
+			lines:    []string{"mpa"},
+			contains: []string{"Unknown command. Did you mean map?\n"},
+		},
+		{
+			name:     "unknown command",
+			lines:    []string{"xyzzy"},
+			contains: []string{"Unknown command\n"},
+		},
+		{
+			name:     "unknown commands are not remembered",
+			lines:    []string{"xyzzy", "history"},
+			contains: []string{"    1  history\n"},
+			absent:   []string{"xyzzy\n"},
+		},
+		{
+			name:     "pipeline",
+			lines:    []string{"map | count"},
+			contains: []string{"20\n"},
+		},
+		{
+			name:     "chained commands",
+			lines:    []string{"inspect pikachu && map; mapb", "history"},
+			contains: []string{"Error: you have not caught pikachu\n", "you're on the first page\n", "    1  inspect pikachu && map; mapb\n"},
+			absent:   []string{"canalave-city-area"},
+		},
+		{
+			name:     "unknown command in a chain",
+			lines:    []string{"map; mpa"},
+			contains: []string{"Unknown command. Did you mean map?\n"},
+			absent:   []string{"canalave-city-area"},
+		},
+		{
+			name:     "line continuation",
+			lines:    []string{`macro twice \`, `  map \`, `  mapb`, "macro"},
+			contains: []string{"Pokedex > > > ", "twice: map; mapb\n"},
+		},
+		{
+			name:     "invalid quoting",
+			lines:    []string{`catch "mr mime`},
+			contains: []string{"Error: unterminated \" quote\n"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cfg := newTestConfig(t)
+			input := strings.NewReader(strings.Join(c.lines, "\n") + "\n")
+			repl, out := newTestRepl(t, cfg, func(out io.Writer) replInput {
+				return newLineInput(input, out)
+			})
+
+			if err := repl.Run(); err != nil {
+				t.Fatal(err)
+			}
+
+			for _, expected := range c.contains {
+				if !strings.Contains(out.String(), expected) {
+					t.Errorf("expected output to contain %q, got:\n%s", expected, out.String())
+				}
+			}
+			for _, unexpected := range c.absent {
+				if strings.Contains(out.String(), unexpected) {
+					t.Errorf("expected output not to contain %q, got:\n%s", unexpected, out.String())
+				}
+			}
+		})
+	}
+}
+
+// keyEvents returns the events for typing text, where '\n' is Enter, '↑'
+// is Up, '↓' is Down, '←' is Left, '␃' is Ctrl-C and '␄' is Ctrl-D.
+func keyEvents(text string) []lineedit.Event {
+	var events []lineedit.Event
+	for _, r := range text {
+		switch r {
+		case '\n':
+			events = append(events, lineedit.Event{Key: lineedit.KeyEnter})
+		case '↑':
+			events = append(events, lineedit.Event{Key: lineedit.KeyUp})
+		case '↓':
+			events = append(events, lineedit.Event{Key: lineedit.KeyDown})
+		case '←':
+			events = append(events, lineedit.Event{Key: lineedit.KeyLeft})
+		case '␃':
+			events = append(events, lineedit.Event{Key: lineedit.KeyCtrlC})
+		case '␄':
+			events = append(events, lineedit.Event{Key: lineedit.KeyCtrlD})
+		default:
+			events = append(events, lineedit.Event{Key: lineedit.KeyRune, Rune: r})
+		}
+	}
+	return events
+}
+
+func TestReplKeys(t *testing.T) {
+	cases := []struct {
+		name    string
+		keys    string
+		history []string
+		output  string
+	}{
+		{name: "typed lines", keys: "map\npokedex\n", history: []string{"map", "pokedex"}},
+		{name: "up recalls the last line", keys: "map\n↑\n", history: []string{"map", "map"}},
+		{name: "up twice", keys: "map\npokedex\n↑↑\n", history: []string{"map", "pokedex", "map"}},
+		{name: "down goes back", keys: "map\npokedex\n↑↑↓\n", history: []string{"map", "pokedex", "pokedex"}},
+		{name: "edit a recalled line", keys: "map\n↑b\n", history: []string{"map", "mapb"}},
+		{name: "up with no history", keys: "↑help\n", history: []string{"help"}},
+		{name: "ctrl-c clears the line", keys: "catch pikachu␃map\n", history: []string{"map"}, output: "catch pikachu\r\x1b[23C^C\r\n"},
+		{name: "ctrl-d deletes", keys: "mapx←␄\n", history: []string{"map"}},
+		{name: "ctrl-d on an empty line exits", keys: "map\n␄pokedex\n", history: []string{"map"}, output: "Closing the Pokedex... Goodbye!\n"},
+		{name: "end of input exits", keys: "map\nmapb", history: []string{"map"}, output: "Closing the Pokedex... Goodbye!\n"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cfg := newTestConfig(t)
+			events := keyEvents(c.keys)
+			repl, out := newTestRepl(t, cfg, func(out io.Writer) replInput {
+				editor := lineedit.New(out, defaultPrompt)
+				editor.SetHistory(cfg.history)
+				return &keyInput{
+					editor: editor,
+					nextKey: func() (lineedit.Event, error) {
+						if len(events) == 0 {
+							return lineedit.Event{}, io.EOF
+						}
+						ev := events[0]
+						events = events[1:]
+						return ev, nil
+					},
+				}
+			})
+
+			if err := repl.Run(); err != nil {
+				t.Fatal(err)
+			}
+			if actual := cfg.history.Entries(); !reflect.DeepEqual(actual, c.history) {
+				t.Errorf("expected history %q, got %q", c.history, actual)
+			}
+			if !strings.Contains(out.String(), c.output) {
+				t.Errorf("expected output to contain %q, got %q", c.output, out.String())
+			}
+		})
+	}
+}
+
+func TestReplKeyError(t *testing.T) {
+	cfg := newTestConfig(t)
+	repl, _ := newTestRepl(t, cfg, func(out io.Writer) replInput {
+		return &keyInput{
+			editor: lineedit.New(out, defaultPrompt),
+			nextKey: func() (lineedit.Event, error) {
+				return lineedit.Event{}, errors.New("read /dev/tty: input/output error")
+			},
+		}
+	})
+
+	if err := repl.Run(); err == nil || err.Error() != "read /dev/tty: input/output error" {
+		t.Errorf("expected the key error to end the REPL, got %v", err)
+	}
+}
+
+func TestReplCatchUsesRand(t *testing.T) {
+	// Pikachu's base experience is 112, and a catch needs a roll above 56:
+	// the first roll is 97 with seed 1 and 18 with seed 2.
+	cases := []struct {
+		seed     int64
+		expected string
+	}{
+		{seed: 1, expected: "pikachu was caught!\n"},
+		{seed: 2, expected: "pikachu escaped!\n"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.expected, func(t *testing.T) {
+			cfg := newTestConfig(t)
+			cfg.rand = rand.New(rand.NewSource(c.seed))
+			repl, out := newTestRepl(t, cfg, func(out io.Writer) replInput {
+				return newLineInput(strings.NewReader("catch pikachu\n"), out)
+			})
+
+			if err := repl.Run(); err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(out.String(), c.expected) {
+				t.Errorf("expected output to contain %q, got:\n%s", c.expected, out.String())
+			}
+		})
+	}
+}
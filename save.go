@@ -0,0 +1,226 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"pokedexcli/internal/atomicfile"
+	"time"
+)
+
+// saveVersion is the version of the save file format written by save.
+// Every older version has a migration in saveMigrations.
+const saveVersion = 1
+
+// saveMigrations bring older save files up to date: saveMigrations[i]
+// turns a version i+1 file into a version i+2 one. When Pokemon gains a
+// field, adding it to savedPokemon is enough if its zero value suits old
+// saves. Otherwise bump saveVersion and add a migration that fills it in.
+var saveMigrations []func(save map[string]any) error
+
+// saveFile is the JSON the caught Pokemon are saved as.
+type saveFile struct {
+	Version int            `json:"version"`
+	SavedAt time.Time      `json:"saved_at"`
+	Pokemon []savedPokemon `json:"pokemon"`
+}
+
+// savedPokemon is one caught Pokemon in a save file.
+type savedPokemon struct {
+	Name     string         `json:"name"`
+	Height   int            `json:"height"`
+	Weight   int            `json:"weight"`
+	Types    []string       `json:"types"`
+	Stats    map[string]int `json:"stats"`
+	CaughtAt time.Time      `json:"caught_at"`
+}
+
+// backupPath is where saving keeps the previous save file at path.
+func backupPath(path string) string {
+	return path + ".bak"
+}
+
+// savePokedex writes the caught Pokemon to path, keeping the file it
+// replaces as a backup.
+func savePokedex(path string, now time.Time) error {
+	save := saveFile{Version: saveVersion, SavedAt: now.UTC(), Pokemon: []savedPokemon{}}
+	for _, name := range sortedKeys(pokedex) {
+		pokemon := pokedex[name]
+		save.Pokemon = append(save.Pokemon, savedPokemon{
+			Name:     pokemon.name,
+			Height:   pokemon.height,
+			Weight:   pokemon.weight,
+			Types:    pokemon.types,
+			Stats:    pokemon.stats,
+			CaughtAt: pokemon.caughtAt.UTC(),
+		})
+	}
+
+	data, err := json.MarshalIndent(save, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	previous, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		if err := atomicfile.Write(backupPath(path), previous); err != nil {
+			return err
+		}
+	case !errors.Is(err, fs.ErrNotExist):
+		return err
+	}
+
+	return atomicfile.Write(path, append(data, '\n'))
+}
+
+// loadPokedex reads the Pokemon saved at path, migrating the file from an
+// older version if need be.
+func loadPokedex(path string) (map[string]Pokemon, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err = migrateSave(data, saveMigrations)
+	if err != nil {
+		return nil, err
+	}
+
+	var save saveFile
+	if err := json.Unmarshal(data, &save); err != nil {
+		return nil, err
+	}
+
+	caught := make(map[string]Pokemon, len(save.Pokemon))
+	for _, saved := range save.Pokemon {
+		caught[saved.Name] = Pokemon{
+			name:     saved.Name,
+			height:   saved.Height,
+			weight:   saved.Weight,
+			types:    saved.Types,
+			stats:    saved.Stats,
+			caughtAt: saved.CaughtAt,
+		}
+	}
+
+	return caught, nil
+}
+
+// migrateSave runs the migrations a save file needs to reach version
+// len(migrations)+1.
+func migrateSave(data []byte, migrations []func(save map[string]any) error) ([]byte, error) {
+	var save map[string]any
+	if err := json.Unmarshal(data, &save); err != nil {
+		return nil, err
+	}
+
+	version, ok := save["version"].(float64)
+	if !ok || version < 1 || version != float64(int(version)) {
+		return nil, errors.New("not a save file: missing or invalid version")
+	}
+	latest := len(migrations) + 1
+	if int(version) > latest {
+		return nil, fmt.Errorf("save file version %d is newer than this program supports (%d)", int(version), latest)
+	}
+	if int(version) == latest {
+		return data, nil
+	}
+
+	for v := int(version); v < latest; v++ {
+		if err := migrations[v-1](save); err != nil {
+			return nil, fmt.Errorf("migrating save file from version %d: %w", v, err)
+		}
+		save["version"] = v + 1
+	}
+
+	return json.Marshal(save)
+}
+
+// loadSaveFile loads the Pokemon saved at path, if it exists, and saves to
+// it from then on. A file that cannot be loaded is left alone, along with
+// its backup, and nothing is saved.
+func loadSaveFile(cfg *config, path string) error {
+	caught, err := loadPokedex(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("loading %s: %w (not saving caught Pokemon this session; the save before it is in %s)", path, err, backupPath(path))
+	}
+	if err == nil {
+		pokedex = caught
+		cfg.lead = firstCaught(caught)
+	}
+
+	cfg.savePath = path
+	return nil
+}
+
+// firstCaught returns the name of the Pokemon caught earliest, which leads
+// the party, or "" if none has been caught. Pokemon caught at the same time,
+// or saved before catch times were, go by name.
+func firstCaught(caught map[string]Pokemon) string {
+	first := ""
+	for _, name := range sortedKeys(caught) {
+		if first == "" || caught[name].caughtAt.Before(caught[first].caughtAt) {
+			first = name
+		}
+	}
+	return first
+}
+
+// autosave saves the caught Pokemon to the save file, if there is one.
+func (cfg *config) autosave() error {
+	if cfg.savePath == "" {
+		return nil
+	}
+	if err := savePokedex(cfg.savePath, cfg.now()); err != nil {
+		return fmt.Errorf("saving the Pokedex: %w", err)
+	}
+	return nil
+}
+
+func commandSave(cfg *config, args commandArgs) (result, error) {
+	path := args.arg(0)
+	if path == "" {
+		path = cfg.savePath
+	}
+	if path == "" {
+		return nil, errors.New("no save file: name one, or start with --save")
+	}
+
+	if err := savePokedex(path, cfg.now()); err != nil {
+		return nil, err
+	}
+	return textResult{fmt.Sprintf("Saved %d Pokemon to %s", len(pokedex), path)}, nil
+}
+
+func commandLoad(cfg *config, args commandArgs) (result, error) {
+	path := args.arg(0)
+	if path == "" {
+		path = cfg.savePath
+	}
+	if path == "" {
+		return nil, errors.New("no save file: name one, or start with --save")
+	}
+
+	caught, err := loadPokedex(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(pokedex) > 0 && cfg.confirm != nil &&
+		!cfg.confirm(fmt.Sprintf("Replace the %d Pokemon you have caught?", len(pokedex))) {
+		return nil, nil
+	}
+
+	pokedex = caught
+	cfg.lead = firstCaught(caught)
+
+	lines := textResult{fmt.Sprintf("Loaded %d Pokemon from %s", len(pokedex), path)}
+	if path != cfg.savePath {
+		if err := cfg.autosave(); err != nil {
+			return nil, err
+		}
+	}
+	return lines, nil
+}
@@ -0,0 +1,239 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSaveAndLoadPokedex(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pokedex.json")
+	caughtAt := time.Date(2024, 4, 30, 18, 0, 0, 0, time.UTC)
+	pikachu := Pokemon{name: "pikachu", height: 4, weight: 60, types: []string{"electric"}, stats: map[string]int{"speed": 90}, caughtAt: caughtAt}
+	pokedex = map[string]Pokemon{"pikachu": pikachu}
+
+	now := time.Date(2024, 5, 1, 9, 30, 0, 0, time.UTC)
+	if err := savePokedex(path, now); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(backupPath(path)); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected no backup of a new save file, got %v", err)
+	}
+
+	first, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{
+  "version": 1,
+  "saved_at": "2024-05-01T09:30:00Z",
+  "pokemon": [
+    {
+      "name": "pikachu",
+      "height": 4,
+      "weight": 60,
+      "types": [
+        "electric"
+      ],
+      "stats": {
+        "speed": 90
+      },
+      "caught_at": "2024-04-30T18:00:00Z"
+    }
+  ]
+}
+`
+	if string(first) != expected {
+		t.Errorf("expected save file:\n%s\ngot:\n%s", expected, first)
+	}
+
+	pokedex["psyduck"] = Pokemon{name: "psyduck", types: []string{"water"}}
+	if err := savePokedex(path, now); err != nil {
+		t.Fatal(err)
+	}
+	backup, err := os.ReadFile(backupPath(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(backup) != string(first) {
+		t.Errorf("expected the backup to hold the previous save, got:\n%s", backup)
+	}
+
+	caught, err := loadPokedex(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(caught["pikachu"], pikachu) || caught["psyduck"].name != "psyduck" || len(caught) != 2 {
+		t.Errorf("expected the saved Pokemon back, got %+v", caught)
+	}
+}
+
+func TestMigrateSave(t *testing.T) {
+	migrations := []func(save map[string]any) error{
+		// Version 2 gave every Pokemon a nickname, defaulting to its name.
+		func(save map[string]any) error {
+			for _, p := range save["pokemon"].([]any) {
+				pokemon := p.(map[string]any)
+				pokemon["nickname"] = pokemon["name"]
+			}
+			return nil
+		},
+		func(save map[string]any) error {
+			return errors.New("unsupported")
+		},
+	}
+
+	data, err := migrateSave([]byte(`{"version": 1, "pokemon": [{"name": "pikachu"}]}`), migrations[:1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var save map[string]any
+	if err := json.Unmarshal(data, &save); err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]any{
+		"version": 2.0,
+		"pokemon": []any{map[string]any{"name": "pikachu", "nickname": "pikachu"}},
+	}
+	if !reflect.DeepEqual(save, expected) {
+		t.Errorf("expected %v, got %v", expected, save)
+	}
+
+	current := []byte(`{"version": 2, "pokemon": []}`)
+	if data, err := migrateSave(current, migrations[:1]); err != nil || string(data) != string(current) {
+		t.Errorf("expected a current file unchanged, got %s, %v", data, err)
+	}
+
+	errorCases := map[string]string{
+		`{"version": 3}`:   "save file version 3 is newer than this program supports (2)",
+		`{"pokemon": []}`:  "not a save file: missing or invalid version",
+		`{"version": 1.5}`: "not a save file: missing or invalid version",
+	}
+	for input, expected := range errorCases {
+		if _, err := migrateSave([]byte(input), migrations[:1]); err == nil || err.Error() != expected {
+			t.Errorf("%s: expected error %q, got %v", input, expected, err)
+		}
+	}
+
+	if _, err := migrateSave([]byte(`{"version": 1, "pokemon": []}`), migrations); err == nil || err.Error() != "migrating save file from version 2: unsupported" {
+		t.Errorf("expected a failed migration, got %v", err)
+	}
+
+	if len(saveMigrations) != saveVersion-1 {
+		t.Errorf("expected %d migrations for save version %d, got %d", saveVersion-1, saveVersion, len(saveMigrations))
+	}
+}
+
+func TestLoadSaveFile(t *testing.T) {
+	dir := t.TempDir()
+
+	cfg := newTestConfig(t)
+	missing := filepath.Join(dir, "missing.json")
+	if err := loadSaveFile(cfg, missing); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.savePath != missing {
+		t.Errorf("expected to save to a new file, got %q", cfg.savePath)
+	}
+
+	cfg = newTestConfig(t)
+	saved := filepath.Join(dir, "saved.json")
+	first := cfg.now().Add(-time.Hour)
+	pokedex = map[string]Pokemon{
+		"bulbasaur": {name: "bulbasaur", caughtAt: cfg.now()},
+		"psyduck":   {name: "psyduck", caughtAt: first},
+	}
+	if err := savePokedex(saved, cfg.now()); err != nil {
+		t.Fatal(err)
+	}
+	pokedex = make(map[string]Pokemon)
+	if err := loadSaveFile(cfg, saved); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.lead != "psyduck" {
+		t.Errorf("expected the Pokemon caught first to lead, got %q", cfg.lead)
+	}
+
+	cfg = newTestConfig(t)
+	broken := filepath.Join(dir, "broken.json")
+	if err := os.WriteFile(broken, []byte("{"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := loadSaveFile(cfg, broken); err == nil || !strings.Contains(err.Error(), broken+".bak") {
+		t.Errorf("expected an error naming the backup, got %v", err)
+	}
+	if cfg.savePath != "" {
+		t.Errorf("expected not to save over a broken file, got %q", cfg.savePath)
+	}
+}
+
+func TestCatchSavesPokedex(t *testing.T) {
+	cfg := newTestConfig(t)
+	cfg.savePath = filepath.Join(t.TempDir(), "pokedex.json")
+
+	if err := runLine(cfg, "catch pikachu", failOnReport(t)); err != nil {
+		t.Fatal(err)
+	}
+
+	caught, err := loadPokedex(cfg.savePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !caught["pikachu"].caughtAt.Equal(cfg.now()) {
+		t.Errorf("expected pikachu to be saved with the time it was caught, got %+v", caught)
+	}
+}
+
+func TestSaveAndLoadCommands(t *testing.T) {
+	dir := t.TempDir()
+	cfg := newTestConfig(t)
+	cfg.savePath = filepath.Join(dir, "pokedex.json")
+	other := filepath.Join(dir, "other.json")
+
+	pokedex["pikachu"] = Pokemon{name: "pikachu"}
+	cfg.lead = "pikachu"
+	script := "save " + other + "\nsave\n"
+	if status := runScript(cfg, strings.NewReader(script)); status != 0 {
+		t.Errorf("expected status 0, got %d", status)
+	}
+	output := takeOutput(cfg)
+	expected := "Saved 1 Pokemon to " + other + "\nSaved 1 Pokemon to " + cfg.savePath + "\n"
+	if output != expected {
+		t.Errorf("expected %q, got %q", expected, output)
+	}
+
+	pokedex = map[string]Pokemon{"psyduck": {name: "psyduck"}}
+	if err := savePokedex(other, cfg.now()); err != nil {
+		t.Fatal(err)
+	}
+	pokedex = map[string]Pokemon{"pikachu": {name: "pikachu"}}
+
+	if err := runLine(cfg, "load "+other, failOnReport(t)); err != nil {
+		t.Fatal(err)
+	}
+	output = takeOutput(cfg)
+	if output != "Loaded 1 Pokemon from "+other+"\n" {
+		t.Errorf("unexpected output %q", output)
+	}
+	if _, ok := pokedex["psyduck"]; !ok || len(pokedex) != 1 || cfg.lead != "psyduck" {
+		t.Errorf("expected the loaded Pokemon to replace the caught ones and lead, got %+v, lead %q", pokedex, cfg.lead)
+	}
+
+	saved, err := loadPokedex(cfg.savePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := saved["psyduck"]; !ok {
+		t.Errorf("expected loading another file to save it as the Pokedex, got %+v", saved)
+	}
+
+	cfg.savePath = ""
+	if err := runLine(cfg, "save", failOnReport(t)); err == nil || err.Error() != "no save file: name one, or start with --save" {
+		t.Errorf("expected an error without a save file, got %v", err)
+	}
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// errExit is returned by the exit command to stop reading commands.
+var errExit = errors.New("exit")
+
+// runScript runs each line of r as a command line, skipping blank lines and
+// lines starting with #. A line ending in a backslash continues on the next
+// one. Every line runs even if an earlier one failed; the returned exit
+// status is 1 if any command failed.
+func runScript(cfg *config, r io.Reader) int {
+	status := 0
+	report := func(err error) {
+		fmt.Fprintln(cfg.errOut, errorText(errColors, err))
+		status = 1
+	}
+
+	var continued string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if continued == "" && (line == "" || strings.HasPrefix(line, "#")) {
+			continue
+		}
+		line = continued + line
+		if continuesLine(line) {
+			continued = line[:len(line)-1]
+			continue
+		}
+		continued = ""
+
+		if runScriptLine(cfg, line, report) {
+			return status
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		report(err)
+	} else if continued != "" {
+		report(errors.New("trailing backslash at the end of the input"))
+	}
+
+	return status
+}
+
+// runScriptLine runs one line of a script, recording it if a transcript is
+// being recorded, and reports whether it asked to exit.
+func runScriptLine(cfg *config, line string, report func(error)) bool {
+	report, recorded := cfg.recordLine(line, report)
+	defer recorded()
+
+	err := runLine(cfg, line, report)
+	if err != nil && !errors.Is(err, errExit) {
+		report(err)
+	}
+	return errors.Is(err, errExit)
+}
+
+// runLine runs a command line of pipelines separated by ";" or "&&". It
+// returns the error of the last pipeline that ran, as the status of the
+// whole line, and passes report the errors of those before it.
+func runLine(cfg *config, input string, report func(error)) error {
+	links, err := splitCommandLine(input)
+	if err != nil {
+		return err
+	}
+
+	return runChain(cfg, links, report)
+}
+
+// runChain runs the pipelines of a command line in turn. One after "&&" is
+// skipped if the one before it failed or was skipped. The exit command
+// stops the chain, returning errExit.
+func runChain(cfg *config, links []chainLink, report func(error)) error {
+	res, err := evalChain(cfg, links, nil, report)
+	if res != nil {
+		if err := cfg.show(res); err != nil {
+			return err
+		}
+	}
+	return err
+}
+
+// evalChain runs the pipelines of a command line as runChain does, showing
+// the results of all but the last and returning that one's.
+func evalChain(cfg *config, links []chainLink, expanding []string, report func(error)) (result, error) {
+	var res result
+	var err error
+	for _, link := range links {
+		if link.andThen && err != nil {
+			continue
+		}
+		if err != nil {
+			report(err)
+		}
+		if res != nil {
+			if err := cfg.show(res); err != nil {
+				return nil, err
+			}
+		}
+
+		res, err = evalPipeline(cfg, link.stages, expanding, report)
+		if errors.Is(err, errExit) {
+			return nil, err
+		}
+	}
+
+	return res, err
+}
+
+// runCommand runs the command named by args[0] with the rest of args and
+// shows its result. Errors from a macro step that carries on past them are
+// written to cfg.errOut.
+func runCommand(cfg *config, args []string) error {
+	return runChain(cfg, []chainLink{{stages: [][]string{args}}}, func(err error) {
+		fmt.Fprintln(cfg.errOut, errorText(errColors, err))
+	})
+}
+
+// evalPipeline runs the command in stages[0], passes its result through the
+// filters in the remaining stages and returns what is left.
+func evalPipeline(cfg *config, stages [][]string, expanding []string, report func(error)) (result, error) {
+	if len(stages) > 1 && slices.ContainsFunc(stages, func(stage []string) bool { return len(stage) == 0 }) {
+		return nil, errors.New("empty command in pipeline")
+	}
+
+	filters, err := parseFilters(stages[1:])
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := evalCommand(cfg, stages[0], expanding, report)
+	if err != nil {
+		return nil, err
+	}
+	if res == nil {
+		if len(filters) == 0 {
+			return nil, nil
+		}
+		res = textResult{}
+	}
+
+	for _, filter := range filters {
+		if res, err = filter(res); err != nil {
+			return nil, err
+		}
+	}
+
+	return res, nil
+}
+
+// evalCommand runs args, expanding aliases and macros before looking up
+// the command, and returns its result. A macro's steps are command lines
+// of their own; the macro shows the results of all but its last step as it
+// goes and stops at the first step that fails. expanding holds the aliases
+// and macros already being expanded, so one that ends up running itself is
+// stopped.
+func evalCommand(cfg *config, args []string, expanding []string, report func(error)) (result, error) {
+	if len(args) == 0 {
+		return nil, nil
+	}
+
+	name := strings.ToLower(args[0])
+	steps, ok, err := expandUserCommand(cfg, name, args[1:])
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		if i := slices.Index(expanding, name); i >= 0 {
+			return nil, cycleError(append(expanding[i:], name))
+		}
+		expanding = append(slices.Clip(expanding), name)
+
+		var res result
+		for _, step := range steps {
+			if res != nil {
+				if err := cfg.show(res); err != nil {
+					return nil, err
+				}
+			}
+			if res, err = evalChain(cfg, step, expanding, report); err != nil {
+				return nil, err
+			}
+		}
+		return res, nil
+	}
+
+	command, ok := lookupCommand(name)
+	if !ok {
+		return nil, unknownCommandError(args[0])
+	}
+
+	parsed, err := command.parse(args[1:])
+	if err != nil {
+		return nil, err
+	}
+
+	return command.callback(cfg, parsed)
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRunScript(t *testing.T) {
+	cases := []struct {
+		name       string
+		script     string
+		status     int
+		expected   []string
+		unexpected []string
+	}{
+		{
+			name:     "runs every line",
+			script:   "# find somewhere to fish\nexplore pastoria-city-area\n\nhistory\n",
+			status:   0,
+			expected: []string{"Exploring pastoria-city-area...\n", " - magikarp\n"},
+		},
+		{
+			name:     "failures set the status but keep going",
+			script:   "explore nowhere-area\nexplore canalave-city-area\n",
+			status:   1,
+			expected: []string{"Exploring canalave-city-area...\n"},
+		},
+		{
+			name:   "unknown commands fail",
+			script: "fly pallet-town\n",
+			status: 1,
+		},
+		{
+			name:     "semicolons run every command",
+			script:   "explore nowhere-area; explore canalave-city-area\n",
+			status:   1,
+			expected: []string{"Exploring canalave-city-area...\n"},
+		},
+		{
+			name:       "&& stops at the first failure",
+			script:     "explore nowhere-area && explore canalave-city-area; explore pastoria-city-area\n",
+			status:     1,
+			expected:   []string{"Exploring pastoria-city-area...\n"},
+			unexpected: []string{"Exploring canalave-city-area"},
+		},
+		{
+			name:     "&& runs the next command on success",
+			script:   "explore canalave-city-area && explore pastoria-city-area\n",
+			status:   0,
+			expected: []string{"Exploring canalave-city-area...\n", "Exploring pastoria-city-area...\n"},
+		},
+		{
+			name:     "backslash continues a line",
+			script:   "macro fish \\\n  'explore pastoria-city-area' \\\n  'explore canalave-city-area'\nfish\n",
+			status:   0,
+			expected: []string{"Exploring pastoria-city-area...\n", "Exploring canalave-city-area...\n"},
+		},
+		{
+			name:   "backslash at the end of the script",
+			script: "explore \\\n",
+			status: 1,
+		},
+		{
+			name:       "exit stops the script",
+			script:     "exit; explore pastoria-city-area\nexplore canalave-city-area\n",
+			status:     0,
+			expected:   []string{"Closing the Pokedex... Goodbye!\n"},
+			unexpected: []string{"Exploring"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cfg := newTestConfig(t)
+
+			status := runScript(cfg, strings.NewReader(c.script))
+			output := takeOutput(cfg)
+
+			if status != c.status {
+				t.Errorf("expected status %d, got %d", c.status, status)
+			}
+			for _, expected := range c.expected {
+				if !strings.Contains(output, expected) {
+					t.Errorf("expected output to contain %q, got:\n%s", expected, output)
+				}
+			}
+			for _, unexpected := range c.unexpected {
+				if strings.Contains(output, unexpected) {
+					t.Errorf("expected output not to contain %q, got:\n%s", unexpected, output)
+				}
+			}
+		})
+	}
+}
+
+// failOnReport returns a report function for runLine that fails the test
+// on any error it is given.
+func failOnReport(t *testing.T) func(error) {
+	return func(err error) {
+		t.Helper()
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"pokedexcli/internal/render"
+	"strings"
+)
+
+// sessionSetting is something the set command can change until the REPL
+// exits.
+type sessionSetting struct {
+	name        string
+	description string
+	get         func(cfg *config) string
+	set         func(cfg *config, value string) error
+}
+
+var sessionSettings = []sessionSetting{
+	{
+		name:        "output",
+		description: "format for map, explore, inspect and pokedex: text, json, yaml, csv or table",
+		get:         func(cfg *config) string { return string(cfg.output) },
+		set: func(cfg *config, value string) error {
+			format, err := render.ParseFormat(value)
+			if err != nil {
+				return err
+			}
+			cfg.output = format
+			return nil
+		},
+	},
+	{
+		name:        "pager",
+		description: "show output taller than the screen in $PAGER or the built-in pager: on or off",
+		get: func(cfg *config) string {
+			if cfg.paging {
+				return "on"
+			}
+			return "off"
+		},
+		set: func(cfg *config, value string) error {
+			switch strings.ToLower(value) {
+			case "on":
+				cfg.paging = true
+			case "off":
+				cfg.paging = false
+			default:
+				return fmt.Errorf("invalid value %q for pager: expected on or off", value)
+			}
+			return nil
+		},
+	},
+}
+
+func lookupSessionSetting(name string) (sessionSetting, bool) {
+	for _, setting := range sessionSettings {
+		if setting.name == strings.ToLower(name) {
+			return setting, true
+		}
+	}
+
+	return sessionSetting{}, false
+}
+
+func commandSet(cfg *config, args commandArgs) (result, error) {
+	if len(args.positional) == 0 {
+		lines := textResult{}
+		for _, setting := range sessionSettings {
+			lines = append(lines, setting.name+": "+setting.get(cfg))
+		}
+		return lines, nil
+	}
+
+	setting, ok := lookupSessionSetting(args.arg(0))
+	if !ok {
+		return nil, fmt.Errorf("unknown setting %q", args.arg(0))
+	}
+
+	if len(args.positional) == 1 {
+		return textResult{setting.name + ": " + setting.get(cfg)}, nil
+	}
+
+	return nil, setting.set(cfg, args.arg(1))
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"pokedexcli/internal/atomicfile"
+	"pokedexcli/internal/pokeapi"
+)
+
+// settings are read from a JSON config file. Missing fields keep their
+// defaults.
+type settings struct {
+	Backend       string `json:"backend"`
+	GraphQLURL    string `json:"graphql_url"`
+	HistoryFile   string `json:"history_file"`
+	HistorySize   int    `json:"history_size"`
+	HistoryDedupe bool   `json:"history_dedupe"`
+	SaveFile      string `json:"save_file"`
+
+	// Prompt is a text/template for the REPL prompt; see promptData for
+	// what it can show. Theme names the color theme.
+	Prompt string `json:"prompt"`
+	Theme  string `json:"theme"`
+
+	// Aliases and Macros are the user's own commands, managed with the
+	// alias and macro commands.
+	Aliases map[string]string   `json:"aliases,omitempty"`
+	Macros  map[string][]string `json:"macros,omitempty"`
+}
+
+// defaultSettings are the settings of the default profile before its
+// config file is read.
+func defaultSettings() settings {
+	return defaultSettingsIn(configDir())
+}
+
+// defaultSettingsIn are the default settings for a profile whose files are
+// in dir, or that has no files if dir is "".
+func defaultSettingsIn(dir string) settings {
+	s := settings{
+		Backend:       "rest",
+		GraphQLURL:    pokeapi.GraphQLURL,
+		HistorySize:   1000,
+		HistoryDedupe: true,
+		Prompt:        defaultPromptTemplate,
+		Theme:         "default",
+	}
+
+	if dir != "" {
+		s.HistoryFile = filepath.Join(dir, "history")
+		s.SaveFile = filepath.Join(dir, "pokedex.json")
+	}
+
+	return s
+}
+
+// configDir is the pokedexcli directory in the user's config directory, or
+// "" if there is none.
+func configDir() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Join(dir, "pokedexcli")
+}
+
+// loadSettings reads the config file at path. A missing file is not an error.
+func loadSettings(path string) (settings, error) {
+	return loadSettingsOver(path, defaultSettings())
+}
+
+// loadSettingsOver reads the config file at path over the settings s.
+func loadSettingsOver(path string, s settings) (settings, error) {
+	if path == "" {
+		return s, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return s, err
+	}
+
+	if err := json.Unmarshal(data, &s); err != nil {
+		return s, err
+	}
+
+	return s, nil
+}
+
+// saveSetting sets one field of the config file at path, or removes it if
+// value is nil, leaving the rest of the file as the user wrote it.
+func saveSetting(path, key string, value any) error {
+	fields := make(map[string]json.RawMessage)
+
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &fields); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+
+	if value == nil {
+		delete(fields, key)
+	} else {
+		raw, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		fields[key] = raw
+	}
+
+	data, err = json.MarshalIndent(fields, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return atomicfile.Write(path, append(data, '\n'))
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadSettings(t *testing.T) {
+	dir := t.TempDir()
+
+	s, err := loadSettings(filepath.Join(dir, "missing.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(s, defaultSettings()) {
+		t.Errorf("expected defaults for a missing file, got %+v", s)
+	}
+
+	path := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(path, []byte(`{"backend": "graphql"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err = loadSettings(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Backend != "graphql" {
+		t.Errorf("expected backend from the file, got %s", s.Backend)
+	}
+	if s.GraphQLURL != defaultSettings().GraphQLURL {
+		t.Errorf("expected unset fields to keep their defaults, got %s", s.GraphQLURL)
+	}
+}
+
+func TestNewPokeapiClientBackends(t *testing.T) {
+	for _, backend := range []string{"rest", "graphql"} {
+		s := defaultSettings()
+		s.Backend = backend
+		if _, err := newPokeapiClient(s, "", t.TempDir()); err != nil {
+			t.Errorf("%s: %v", backend, err)
+		}
+	}
+
+	s := defaultSettings()
+	s.Backend = "soap"
+	if _, err := newPokeapiClient(s, "", t.TempDir()); err == nil {
+		t.Errorf("expected an error for an unknown backend")
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"pokedexcli/internal/fuzzy"
+	"slices"
+)
+
+// checkName makes sure name is one of names before anything is requested
+// for it. A misspelt name with a close match is offered to the user, when
+// there is one to ask, and the match is returned if they accept. Otherwise
+// notFound is returned, naming the closest match if there is one.
+func checkName(cfg *config, name string, names []string, notFound error) (string, error) {
+	if slices.Contains(names, name) {
+		return name, nil
+	}
+
+	suggestion, ok := fuzzy.Suggest(name, names)
+	if !ok {
+		return "", notFound
+	}
+
+	if cfg.confirm == nil {
+		return "", fmt.Errorf("%w; did you mean %s?", notFound, suggestion)
+	}
+	if cfg.confirm(fmt.Sprintf("Did you mean %s?", suggestion)) {
+		return suggestion, nil
+	}
+
+	return "", notFound
+}
+
+// checkResourceName is checkName against every name in an API resource list.
+// If the list cannot be fetched, name is returned unchecked and the request
+// for it decides whether it exists.
+func checkResourceName(cfg *config, resource, name string, notFound error) (string, error) {
+	names, err := cfg.pokeapiClient.ResourceNames(resource)
+	if err != nil {
+		return name, nil
+	}
+
+	return checkName(cfg, name, names, notFound)
+}
+
+// suggestCommand returns the command name or alias closest to a mistyped
+// one.
+func suggestCommand(name string) (string, bool) {
+	var names []string
+	for _, command := range commands {
+		names = append(names, command.name)
+		names = append(names, command.aliases...)
+	}
+
+	return fuzzy.Suggest(name, names)
+}
+
+// unknownCommandError reports a command that does not exist, suggesting
+// the closest one.
+func unknownCommandError(name string) error {
+	if suggestion, ok := suggestCommand(name); ok {
+		return fmt.Errorf("unknown command %q; did you mean %s?", name, suggestion)
+	}
+
+	return fmt.Errorf("unknown command %q", name)
+}
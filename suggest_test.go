@@ -0,0 +1,94 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSuggestions(t *testing.T) {
+	cases := []struct {
+		name     string
+		args     []string
+		answer   *bool
+		expected string
+		err      string
+	}{
+		{
+			name: "unknown command",
+			args: []string{"mpa"},
+			err:  `unknown command "mpa"; did you mean map?`,
+		},
+		{
+			name: "misspelt Pokemon without a prompt",
+			args: []string{"catch", "charmandr"},
+			err:  "Pokemon charmandr not found; did you mean charmander?",
+		},
+		{
+			name:     "accepted suggestion",
+			args:     []string{"catch", "charmandr"},
+			answer:   ptr(true),
+			expected: "Throwing a Pokeball at charmander...\n",
+		},
+		{
+			name:   "declined suggestion",
+			args:   []string{"catch", "charmandr"},
+			answer: ptr(false),
+			err:    "Pokemon charmandr not found",
+		},
+		{
+			name: "no close match",
+			args: []string{"catch", "mewtwo"},
+			err:  "Pokemon mewtwo not found",
+		},
+		{
+			name:     "misspelt location",
+			args:     []string{"explore", "pastoria-cty-area"},
+			answer:   ptr(true),
+			expected: "Exploring pastoria-city-area...\n",
+		},
+		{
+			name: "uncaught Pokemon close to a caught one",
+			args: []string{"inspect", "pikachuu"},
+			err:  "you have not caught pikachuu; did you mean pikachu?",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cfg := newTestConfig(t)
+			pokedex["pikachu"] = Pokemon{name: "pikachu"}
+
+			var asked []string
+			if c.answer != nil {
+				cfg.confirm = func(question string) bool {
+					asked = append(asked, question)
+					return *c.answer
+				}
+			}
+
+			err := runCommand(cfg, c.args)
+			output := takeOutput(cfg)
+			if c.err != "" {
+				if err == nil || err.Error() != c.err {
+					t.Errorf("expected error %q, got %v", c.err, err)
+				}
+				if output != "" {
+					t.Errorf("expected nothing to be requested, got output %q", output)
+				}
+			} else if err != nil {
+				t.Fatal(err)
+			}
+
+			if !strings.Contains(output, c.expected) {
+				t.Errorf("expected output to contain %q, got:\n%s", c.expected, output)
+			}
+			if c.answer != nil && len(asked) != 1 {
+				t.Errorf("expected one question, got %q", asked)
+			}
+		})
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"pokedexcli/internal/lineedit"
+
+	"github.com/eiannone/keyboard"
+	"golang.org/x/term"
+)
+
+// keyBufferSize is how many keys the keyboard package buffers. It must
+// match what keyboard.Open uses, so GetKeys returns the open channel.
+const keyBufferSize = 10
+
+// terminalEvents delivers what happens at the terminal while the program
+// waits for a key: keys, changes of size, and signals asking it to stop.
+// A signal that arrives while a command runs is picked up at the next key.
+type terminalEvents struct {
+	keys    <-chan keyboard.KeyEvent
+	resize  chan os.Signal
+	stop    chan os.Signal
+	stopped bool
+}
+
+// openTerminalEvents starts listening for resizes and stop signals. The
+// keyboard must be open.
+func openTerminalEvents() (*terminalEvents, error) {
+	keys, err := keyboard.GetKeys(keyBufferSize)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &terminalEvents{
+		keys:   keys,
+		resize: make(chan os.Signal, 1),
+		stop:   make(chan os.Signal, 1),
+	}
+	notifyResize(t.resize)
+	signal.Notify(t.stop, stopSignals...)
+
+	return t, nil
+}
+
+// close stops listening for signals.
+func (t *terminalEvents) close() {
+	signal.Stop(t.resize)
+	signal.Stop(t.stop)
+}
+
+// next waits for a key the line editor has a use for, or a resize, which
+// it reports as lineedit.KeyResize. Once a stop signal has arrived it
+// returns io.EOF.
+func (t *terminalEvents) next() (lineedit.Event, error) {
+	for !t.stopped {
+		select {
+		case <-t.stop:
+			t.stopped = true
+		case <-t.resize:
+			return lineedit.Event{Key: lineedit.KeyResize}, nil
+		case key, ok := <-t.keys:
+			if !ok {
+				// The keyboard was closed and opened again, as it is
+				// around an external pager, which makes a new channel.
+				keys, err := keyboard.GetKeys(keyBufferSize)
+				if err != nil {
+					return lineedit.Event{}, err
+				}
+				t.keys = keys
+				continue
+			}
+			if key.Err != nil {
+				return lineedit.Event{}, key.Err
+			}
+			if ev, ok := keyEvent(key.Rune, key.Key); ok {
+				return ev, nil
+			}
+		}
+	}
+
+	return lineedit.Event{}, io.EOF
+}
+
+// keyEvent translates a key from the keyboard package into a line editor
+// event. Keys the editor has no use for are dropped.
+func keyEvent(char rune, key keyboard.Key) (lineedit.Event, bool) {
+	if key == 0 && char != 0 {
+		return lineedit.Event{Key: lineedit.KeyRune, Rune: char}, true
+	}
+
+	switch key {
+	case keyboard.KeySpace:
+		return lineedit.Event{Key: lineedit.KeyRune, Rune: ' '}, true
+	case keyboard.KeyTab:
+		return lineedit.Event{Key: lineedit.KeyTab}, true
+	case keyboard.KeyCtrlR:
+		return lineedit.Event{Key: lineedit.KeyCtrlR}, true
+	case keyboard.KeyCtrlG:
+		return lineedit.Event{Key: lineedit.KeyCtrlG}, true
+	case keyboard.KeyEsc:
+		return lineedit.Event{Key: lineedit.KeyEsc}, true
+	case keyboard.KeyEnter:
+		return lineedit.Event{Key: lineedit.KeyEnter}, true
+	case keyboard.KeyBackspace, keyboard.KeyBackspace2:
+		return lineedit.Event{Key: lineedit.KeyBackspace}, true
+	case keyboard.KeyDelete:
+		return lineedit.Event{Key: lineedit.KeyDelete}, true
+	case keyboard.KeyArrowLeft, keyboard.KeyCtrlB:
+		return lineedit.Event{Key: lineedit.KeyLeft}, true
+	case keyboard.KeyArrowRight, keyboard.KeyCtrlF:
+		return lineedit.Event{Key: lineedit.KeyRight}, true
+	case keyboard.KeyArrowUp, keyboard.KeyCtrlP:
+		return lineedit.Event{Key: lineedit.KeyUp}, true
+	case keyboard.KeyArrowDown, keyboard.KeyCtrlN:
+		return lineedit.Event{Key: lineedit.KeyDown}, true
+	case keyboard.KeyPgup:
+		return lineedit.Event{Key: lineedit.KeyPageUp}, true
+	case keyboard.KeyPgdn:
+		return lineedit.Event{Key: lineedit.KeyPageDown}, true
+	case keyboard.KeyHome:
+		return lineedit.Event{Key: lineedit.KeyHome}, true
+	case keyboard.KeyEnd:
+		return lineedit.Event{Key: lineedit.KeyEnd}, true
+	case keyboard.KeyCtrlA:
+		return lineedit.Event{Key: lineedit.KeyCtrlA}, true
+	case keyboard.KeyCtrlE:
+		return lineedit.Event{Key: lineedit.KeyCtrlE}, true
+	case keyboard.KeyCtrlK:
+		return lineedit.Event{Key: lineedit.KeyCtrlK}, true
+	case keyboard.KeyCtrlU:
+		return lineedit.Event{Key: lineedit.KeyCtrlU}, true
+	case keyboard.KeyCtrlW:
+		return lineedit.Event{Key: lineedit.KeyCtrlW}, true
+	case keyboard.KeyCtrlC:
+		return lineedit.Event{Key: lineedit.KeyCtrlC}, true
+	case keyboard.KeyCtrlD:
+		return lineedit.Event{Key: lineedit.KeyCtrlD}, true
+	}
+
+	return lineedit.Event{}, false
+}
+
+// terminalWidth returns the number of columns in the terminal, or 0 if it
+// cannot be determined.
+func terminalWidth() int {
+	width, _ := terminalSize()
+	return width
+}
+
+// terminalSize returns the number of columns and rows in the terminal, or
+// zeros if they cannot be determined.
+func terminalSize() (int, int) {
+	width, height, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil {
+		return 0, 0
+	}
+
+	return width, height
+}
+
+// confirm asks a yes or no question on out and reads a single key. Only y
+// answers yes.
+func confirm(out io.Writer, question string) bool {
+	fmt.Fprintf(out, "%s [y/N] ", question)
+
+	char, _, err := keyboard.GetKey()
+	if err != nil {
+		fmt.Fprintln(out)
+		return false
+	}
+
+	if char == 'y' || char == 'Y' {
+		fmt.Fprintln(out, "y")
+		return true
+	}
+	fmt.Fprintln(out, "n")
+	return false
+}
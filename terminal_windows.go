@@ -0,0 +1,12 @@
+//go:build windows
+
+package main
+
+import "os"
+
+// stopSignals ask the REPL to exit as if the user had pressed Ctrl-D.
+var stopSignals = []os.Signal{os.Interrupt}
+
+// notifyResize does nothing: Windows has no signal for a console resize.
+// The REPL still picks up the new width at the next prompt.
+func notifyResize(c chan<- os.Signal) {}
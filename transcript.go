@@ -0,0 +1,352 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"math/rand"
+	"os"
+	"pokedexcli/internal/render"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// A transcript is a text file of command lines and what they printed, made
+// by "record start" and run again by replay. Lines starting with # are
+// comments. Each command line starts with the time it ran in brackets, and
+// the lines it printed follow it, indented by two spaces:
+//
+//	# Pokedex transcript started 2024-05-01T09:30:00Z
+//	[2024-05-01T09:30:00Z] catch pikachu
+//	  Throwing a Pokeball at pikachu...
+//	  pikachu was caught!
+type transcriptEntry struct {
+	time   time.Time
+	line   string
+	output []string
+}
+
+// transcriptIndent starts every output line in a transcript.
+const transcriptIndent = "  "
+
+// ansiEscape matches the color sequences themes add, which transcripts
+// leave out.
+var ansiEscape = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+// outputLines splits what a command line printed into the lines a
+// transcript holds.
+func outputLines(output string) []string {
+	output = strings.TrimSuffix(ansiEscape.ReplaceAllString(output, ""), "\n")
+	if output == "" {
+		return nil
+	}
+	return strings.Split(output, "\n")
+}
+
+// transcriptRand returns the random numbers used while recording or
+// replaying a transcript started at started, so that catching goes the
+// same way both times.
+func transcriptRand(started time.Time) *rand.Rand {
+	return rand.New(rand.NewSource(started.Unix()))
+}
+
+// transcriptRecorder writes the command lines run while recording to a
+// transcript file, each as soon as it has finished.
+type transcriptRecorder struct {
+	path string
+	file *os.File
+}
+
+func startRecording(path string, now time.Time) (*transcriptRecorder, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := fmt.Fprintf(file, "# Pokedex transcript started %s\n", now.Format(time.RFC3339)); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return &transcriptRecorder{path: path, file: file}, nil
+}
+
+func (rec *transcriptRecorder) write(entry transcriptEntry) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "[%s] %s\n", entry.time.Format(time.RFC3339), entry.line)
+	for _, line := range entry.output {
+		fmt.Fprintf(&b, "%s%s\n", transcriptIndent, line)
+	}
+
+	_, err := io.WriteString(rec.file, b.String())
+	return err
+}
+
+// recordLine starts adding a command line to the transcript being
+// recorded, if there is one. Until the returned function is called,
+// everything written to the output or the pager is captured, as are the
+// errors passed to the returned report, which passes them on to report.
+// The returned function writes the entry, unless the line stopped the
+// recording, and reports any error doing so.
+func (cfg *config) recordLine(line string, report func(error)) (func(error), func()) {
+	rec := cfg.recorder
+	if rec == nil {
+		return report, func() {}
+	}
+
+	entry := transcriptEntry{time: cfg.now(), line: line}
+	var output strings.Builder
+	out, page := cfg.out, cfg.page
+	cfg.out = io.MultiWriter(out, &output)
+	if page != nil {
+		cfg.page = func(s string) {
+			output.WriteString(s)
+			page(s)
+		}
+	}
+
+	recordError := func(err error) {
+		fmt.Fprintln(&output, errorText(colors, err))
+		report(err)
+	}
+	return recordError, func() {
+		cfg.out, cfg.page = out, page
+		if cfg.recorder != rec {
+			return
+		}
+
+		entry.output = outputLines(output.String())
+		if err := rec.write(entry); err != nil {
+			report(fmt.Errorf("recording to %s: %w", rec.path, err))
+		}
+	}
+}
+
+// stopRecording closes the transcript being recorded, if there is one.
+func (cfg *config) stopRecording() error {
+	rec := cfg.recorder
+	if rec == nil {
+		return nil
+	}
+
+	cfg.recorder = nil
+	return rec.file.Close()
+}
+
+func commandRecord(cfg *config, args commandArgs) (result, error) {
+	switch action := strings.ToLower(args.arg(0)); {
+	case action == "":
+		if cfg.recorder == nil {
+			return textResult{"Not recording"}, nil
+		}
+		return textResult{"Recording to " + cfg.recorder.path}, nil
+	case action == "start" && len(args.positional) == 2:
+		if cfg.recorder != nil {
+			return nil, fmt.Errorf("already recording to %s", cfg.recorder.path)
+		}
+		now := cfg.now()
+		rec, err := startRecording(args.arg(1), now)
+		if err != nil {
+			return nil, err
+		}
+		cfg.recorder = rec
+		cfg.rand = transcriptRand(now)
+		return textResult{"Recording to " + rec.path}, nil
+	case action == "stop" && len(args.positional) == 1:
+		if cfg.recorder == nil {
+			return nil, errors.New("not recording")
+		}
+		path := cfg.recorder.path
+		if err := cfg.stopRecording(); err != nil {
+			return nil, err
+		}
+		return textResult{"Stopped recording to " + path}, nil
+	}
+
+	return nil, usageError{commands["record"], "expected start <file> or stop"}
+}
+
+// transcriptHeader starts the comment on the first line of a transcript,
+// which is followed by the time recording started.
+const transcriptHeader = "# Pokedex transcript started "
+
+// readTranscript parses a transcript, returning its entries and the time
+// it was started, or that of its first entry if it has no header. An empty
+// line within an entry is an empty line of output, so transcripts survive
+// editors that strip trailing spaces.
+func readTranscript(r io.Reader) (time.Time, []transcriptEntry, error) {
+	var started time.Time
+	var entries []transcriptEntry
+
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := scanner.Text()
+
+		switch {
+		case n == 1 && strings.HasPrefix(line, transcriptHeader):
+			t, err := time.Parse(time.RFC3339, strings.TrimPrefix(line, transcriptHeader))
+			if err != nil {
+				return time.Time{}, nil, fmt.Errorf("line %d: invalid time %q", n, strings.TrimPrefix(line, transcriptHeader))
+			}
+			started = t
+		case strings.HasPrefix(line, "#"):
+		case strings.HasPrefix(line, "["):
+			stamp, command, ok := strings.Cut(line[1:], "] ")
+			if !ok {
+				return time.Time{}, nil, fmt.Errorf("line %d: expected [<time>] <command>", n)
+			}
+			t, err := time.Parse(time.RFC3339, stamp)
+			if err != nil {
+				return time.Time{}, nil, fmt.Errorf("line %d: invalid time %q", n, stamp)
+			}
+			if started.IsZero() {
+				started = t
+			}
+			entries = append(entries, transcriptEntry{time: t, line: command})
+		case len(entries) == 0 && line == "":
+		case len(entries) > 0 && (line == "" || strings.HasPrefix(line, transcriptIndent)):
+			entry := &entries[len(entries)-1]
+			entry.output = append(entry.output, strings.TrimPrefix(line, transcriptIndent))
+		default:
+			return time.Time{}, nil, fmt.Errorf("line %d: expected a [<time>] <command> line or output indented by two spaces", n)
+		}
+	}
+
+	return started, entries, scanner.Err()
+}
+
+// replaySession gives a replay a session of its own, so that it neither
+// depends on nor changes the user's: an empty Pokedex that is not saved,
+// aliases and macros that are not saved either, the default output format
+// and paging, no map page or location yet, and the random numbers the
+// transcript was recorded with. The returned function puts the user's
+// session back.
+func (cfg *config) replaySession(started time.Time) func() {
+	caught, lead, savePath := pokedex, cfg.lead, cfg.savePath
+	aliases, macros, settingsPath := cfg.aliases, cfg.macros, cfg.settingsPath
+	output, paging := cfg.output, cfg.paging
+	next, previous, location := cfg.next, cfg.previous, cfg.location
+	locations, encounters := cfg.locations, cfg.encounters
+	random := cfg.rand
+
+	pokedex, cfg.lead, cfg.savePath = make(map[string]Pokemon), "", ""
+	cfg.aliases, cfg.macros, cfg.settingsPath = maps.Clone(aliases), maps.Clone(macros), ""
+	cfg.output, cfg.paging = render.Text, true
+	cfg.next, cfg.previous, cfg.location = "", "", ""
+	cfg.locations, cfg.encounters = nil, nil
+	cfg.rand = transcriptRand(started)
+
+	return func() {
+		pokedex, cfg.lead, cfg.savePath = caught, lead, savePath
+		cfg.aliases, cfg.macros, cfg.settingsPath = aliases, macros, settingsPath
+		cfg.output, cfg.paging = output, paging
+		cfg.next, cfg.previous, cfg.location = next, previous, location
+		cfg.locations, cfg.encounters = locations, encounters
+		cfg.rand = random
+	}
+}
+
+func commandReplay(cfg *config, args commandArgs) (result, error) {
+	if cfg.replaying {
+		return nil, errors.New("cannot replay a transcript from within a replay")
+	}
+
+	file, err := os.Open(args.arg(0))
+	if err != nil {
+		return nil, err
+	}
+	started, entries, err := readTranscript(file)
+	file.Close()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", args.arg(0), err)
+	}
+
+	out, page := cfg.out, cfg.page
+	restore := cfg.replaySession(started)
+	cfg.replaying = true
+
+	diffs := textResult{}
+	differ := 0
+	for _, entry := range entries {
+		var output strings.Builder
+		cfg.out, cfg.page = &output, nil
+
+		printError := func(err error) {
+			fmt.Fprintln(&output, errorText(colors, err))
+		}
+		err := runLine(cfg, entry.line, printError)
+		if errors.Is(err, errExit) {
+			break
+		}
+		if err != nil {
+			printError(err)
+		}
+
+		if diff, same := diffLines(entry.output, outputLines(output.String())); !same {
+			differ++
+			diffs = append(diffs, "$ "+entry.line)
+			diffs = append(diffs, diff...)
+		}
+	}
+	cfg.out, cfg.page = out, page
+	cfg.replaying = false
+	restore()
+
+	if differ > 0 {
+		if err := cfg.show(diffs); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%d of %d commands printed something different", differ, len(entries))
+	}
+
+	if len(entries) == 1 {
+		return textResult{"Replayed 1 command; its output matched"}, nil
+	}
+	return textResult{fmt.Sprintf("Replayed %d commands; every output matched", len(entries))}, nil
+}
+
+// diffLines compares the lines a command printed when it was recorded with
+// those it prints now. It returns every line, prefixed by "  " if both have
+// it, "- " if only the recording does and "+ " if only the replay does,
+// and whether the two are the same.
+func diffLines(expected, actual []string) ([]string, bool) {
+	// common[i][j] is the length of the longest common subsequence of
+	// expected[i:] and actual[j:].
+	common := make([][]int, len(expected)+1)
+	for i := range common {
+		common[i] = make([]int, len(actual)+1)
+	}
+	for i := len(expected) - 1; i >= 0; i-- {
+		for j := len(actual) - 1; j >= 0; j-- {
+			if expected[i] == actual[j] {
+				common[i][j] = common[i+1][j+1] + 1
+			} else {
+				common[i][j] = max(common[i+1][j], common[i][j+1])
+			}
+		}
+	}
+
+	var diff []string
+	same := true
+	i, j := 0, 0
+	for i < len(expected) || j < len(actual) {
+		switch {
+		case i < len(expected) && j < len(actual) && expected[i] == actual[j]:
+			diff = append(diff, "  "+expected[i])
+			i++
+			j++
+		case j == len(actual) || i < len(expected) && common[i+1][j] >= common[i][j+1]:
+			diff = append(diff, "- "+expected[i])
+			same = false
+			i++
+		default:
+			diff = append(diff, "+ "+actual[j])
+			same = false
+			j++
+		}
+	}
+
+	return diff, same
+}
@@ -0,0 +1,282 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRecordTranscript(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.txt")
+
+	cfg := newTestConfig(t)
+	lines := []string{
+		"record start " + path,
+		"catch pikachu && pokedex",
+		"inspect bulbasaur",
+		"record",
+		"record stop",
+		"map",
+	}
+	repl, out := newTestRepl(t, cfg, func(out io.Writer) replInput {
+		return newLineInput(strings.NewReader(strings.Join(lines, "\n")+"\n"), out)
+	})
+	if err := repl.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(out.String(), "Stopped recording to "+path+"\n") {
+		t.Errorf("expected the recording to stop, got:\n%s", out.String())
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `# Pokedex transcript started 2024-05-01T09:30:00Z
+[2024-05-01T09:30:00Z] catch pikachu && pokedex
+  Throwing a Pokeball at pikachu...
+  pikachu escaped!
+  Your Pokedex:
+[2024-05-01T09:30:00Z] inspect bulbasaur
+  Error: you have not caught bulbasaur
+[2024-05-01T09:30:00Z] record
+  Recording to ` + path + `
+`
+	if string(data) != expected {
+		t.Errorf("expected transcript:\n%s\ngot:\n%s", expected, data)
+	}
+}
+
+func TestExitStopsRecording(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.txt")
+
+	cfg := newTestConfig(t)
+	if status := runScript(cfg, strings.NewReader("record start "+path+"\nmapb\nexit\n")); status != 0 {
+		t.Errorf("expected status 0, got %d", status)
+	}
+	if cfg.recorder != nil {
+		t.Error("expected exit to stop recording")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(string(data), "] mapb\n  you're on the first page\n") {
+		t.Errorf("expected the transcript to end with mapb, got:\n%s", data)
+	}
+}
+
+func TestRecordErrors(t *testing.T) {
+	cases := []struct {
+		line string
+		err  string
+	}{
+		{line: "record stop", err: "not recording"},
+		{line: "record start", err: "expected start <file> or stop (usage: record [start <file> | stop])"},
+		{line: "record rewind", err: "expected start <file> or stop (usage: record [start <file> | stop])"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.line, func(t *testing.T) {
+			cfg := newTestConfig(t)
+			err := runLine(cfg, c.line, failOnReport(t))
+			if err == nil || err.Error() != c.err {
+				t.Errorf("expected error %q, got %v", c.err, err)
+			}
+		})
+	}
+}
+
+func TestReplay(t *testing.T) {
+	cases := []struct {
+		name       string
+		transcript string
+		output     string
+		err        string
+	}{
+		{
+			name: "matching output",
+			transcript: `# Pokedex transcript started 2024-05-01T09:30:00Z
+[2024-05-01T09:30:00Z] explore pastoria-city-area | head 2
+  Exploring pastoria-city-area...
+  Found Pokemon:
+   - tentacool
+   - tentacruel
+[2024-05-01T09:30:05Z] inspect pikachu
+  Error: you have not caught pikachu
+`,
+			output: "Replayed 2 commands; every output matched\n",
+		},
+		{
+			name: "different output",
+			transcript: `[2024-05-01T09:30:00Z] explore pastoria-city-area | head 2
+  Exploring pastoria-city-area...
+  Found Pokemon:
+   - magikarp
+   - tentacruel
+[2024-05-01T09:30:05Z] pokedex
+
+  Your Pokedex:
+`,
+			output: `$ explore pastoria-city-area | head 2
+  Exploring pastoria-city-area...
+  Found Pokemon:
+-  - magikarp
++  - tentacool
+   - tentacruel
+$ pokedex
+- 
+  Your Pokedex:
+`,
+			err: "2 of 2 commands printed something different",
+		},
+		{
+			name:       "invalid transcript",
+			transcript: "[2024-05-01T09:30:00Z] map\ncanalave-city-area\n",
+			err:        "line 2: expected a [<time>] <command> line or output indented by two spaces",
+		},
+		{
+			name:       "invalid time",
+			transcript: "[yesterday] map\n",
+			err:        `line 1: invalid time "yesterday"`,
+		},
+		{
+			name:       "nested replay",
+			transcript: "[2024-05-01T09:30:00Z] replay other.txt\n",
+			output:     "$ replay other.txt\n+ Error: cannot replay a transcript from within a replay\n",
+			err:        "1 of 1 commands printed something different",
+		},
+		{
+			name:       "profile change",
+			transcript: "[2024-05-01T09:30:00Z] profile switch default\n",
+			output:     "$ profile switch default\n+ Error: cannot change profiles from within a replay\n",
+			err:        "1 of 1 commands printed something different",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "session.txt")
+			if err := os.WriteFile(path, []byte(c.transcript), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			cfg := newTestConfig(t)
+			err := runLine(cfg, "replay "+path, failOnReport(t))
+			output := takeOutput(cfg)
+
+			if c.err == "" && err != nil {
+				t.Fatal(err)
+			}
+			if c.err != "" && (err == nil || !strings.HasSuffix(err.Error(), c.err)) {
+				t.Errorf("expected error %q, got %v", c.err, err)
+			}
+			if output != c.output {
+				t.Errorf("expected output:\n%q\ngot:\n%q", c.output, output)
+			}
+		})
+	}
+}
+
+func TestReplayIsolatesSession(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "session.txt")
+
+	cfg := newTestConfig(t)
+	lines := []string{
+		"record start " + path,
+		"catch pikachu; catch pikachu; catch pikachu && pokedex",
+		"alias fish explore pastoria-city-area",
+		"record stop",
+	}
+	if status := runScript(cfg, strings.NewReader(strings.Join(lines, "\n"))); status != 0 {
+		t.Fatalf("expected status 0, got %d", status)
+	}
+
+	if data, err := os.ReadFile(path); err != nil || !strings.Contains(string(data), "pikachu was caught!") {
+		t.Fatalf("expected pikachu to be caught while recording, got %s, %v", data, err)
+	}
+
+	cfg = newTestConfig(t)
+	cfg.savePath = filepath.Join(dir, "pokedex.json")
+	pokedex["psyduck"] = Pokemon{name: "psyduck"}
+	cfg.lead = "psyduck"
+	random := cfg.rand
+
+	if err := runLine(cfg, "replay "+path, failOnReport(t)); err != nil {
+		t.Fatal(err)
+	}
+	output := takeOutput(cfg)
+	if output != "Replayed 2 commands; every output matched\n" {
+		t.Errorf("unexpected output %q", output)
+	}
+
+	if _, ok := pokedex["psyduck"]; !ok || len(pokedex) != 1 || cfg.lead != "psyduck" {
+		t.Errorf("expected the Pokedex back as it was, got %+v, lead %q", pokedex, cfg.lead)
+	}
+	if len(cfg.aliases) != 0 {
+		t.Errorf("expected the replayed alias to be dropped, got %v", cfg.aliases)
+	}
+	if cfg.rand != random {
+		t.Error("expected the session's random numbers back")
+	}
+	if _, err := os.Stat(cfg.savePath); !os.IsNotExist(err) {
+		t.Errorf("expected the replay not to save, got %v", err)
+	}
+}
+
+func TestDiffLines(t *testing.T) {
+	diff, same := diffLines([]string{"a", "b", "c"}, []string{"a", "c", "d"})
+	expected := []string{"  a", "- b", "  c", "+ d"}
+	if !reflect.DeepEqual(diff, expected) || same {
+		t.Errorf("expected %q, got %q (same: %t)", expected, diff, same)
+	}
+
+	if _, same := diffLines([]string{"a"}, []string{"a"}); !same {
+		t.Error("expected equal lines to be the same")
+	}
+}
+
+func TestReplayStartsFromDefaults(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.txt")
+
+	cfg := newTestConfig(t)
+	cfg.paging = true
+	lines := []string{
+		"record start " + path,
+		"set output json; map",
+		"record stop",
+		"set output text",
+		"map",
+		"explore pastoria-city-area",
+		"set output csv",
+		"set pager off",
+	}
+	if status := runScript(cfg, strings.NewReader(strings.Join(lines, "\n"))); status != 0 {
+		t.Fatalf("expected status 0, got %d", status)
+	}
+	takeOutput(cfg)
+
+	before := *cfg
+	if err := runLine(cfg, "replay "+path, failOnReport(t)); err != nil {
+		t.Fatal(err)
+	}
+	if output := takeOutput(cfg); output != "Replayed 1 command; its output matched\n" {
+		t.Errorf("unexpected output %q", output)
+	}
+
+	if cfg.output != before.output || cfg.paging != before.paging {
+		t.Errorf("expected output %q and paging %v back, got %q and %v", before.output, before.paging, cfg.output, cfg.paging)
+	}
+	if cfg.next != before.next || cfg.previous != before.previous {
+		t.Errorf("expected the map pages %q and %q back, got %q and %q", before.next, before.previous, cfg.next, cfg.previous)
+	}
+	if cfg.location != before.location || !reflect.DeepEqual(cfg.locations, before.locations) || !reflect.DeepEqual(cfg.encounters, before.encounters) {
+		t.Errorf("expected the location, map page and encounters back, got %q, %v and %v", cfg.location, cfg.locations, cfg.encounters)
+	}
+}